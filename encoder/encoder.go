@@ -1,9 +1,25 @@
+// Package encoder writes bencode.
+//
+// Encoding is the inverse of package parser: any canonical bencode accepted
+// by parser.Parse is re-emitted byte-for-byte by Encode, as is any value
+// ParseWithOptions produces with Numbers set to NumberBigInt or with
+// OrderedDicts set, the latter even for non-canonical key order. Canonical
+// means dictionary keys are sorted and unique and integers and string
+// lengths carry no sign or leading zeros, which parser.ParseStrict checks.
+// Going the other way, parsing the output of Encode yields a value equal to
+// the one encoded whenever that value uses only the types Parse produces.
 package encoder
 
 import (
-	"fmt"
+	"bytes"
+	"io"
+	"math/big"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/kcabhinav/benparse/parser"
 )
 
 func EncodeInteger(value int) string {
@@ -25,77 +41,190 @@ func EncodeString(value string) string {
 	return builder.String()
 }
 
+// EncodeList encodes values as a bencode list. It accepts every type Encode
+// does and panics with Encode's error if a value cannot be encoded.
 func EncodeList(values []interface{}) string {
 	var builder strings.Builder
 	builder.Grow(estimateListSize(values)) // Pre-allocate estimated size
-	builder.WriteByte('l')
-
-	for _, value := range values {
-		switch v := value.(type) {
-		case int:
-			writeIntegerToBuilder(&builder, v)
-		case string:
-			writeStringToBuilder(&builder, v)
-		case []any:
-			builder.WriteString(EncodeList(v))
-		default:
-			panic(fmt.Sprintf("unsupported type: %T", v))
-		}
+	if err := encodeList(&builder, values); err != nil {
+		panic(err)
 	}
-
-	builder.WriteByte('e')
 	return builder.String()
 }
 
+// EncodeDictionary encodes values with keys sorted as raw byte strings, as
+// BEP 3 requires, so equal dictionaries always produce identical bytes. It
+// accepts every type Encode does and panics with Encode's error if a value
+// cannot be encoded.
 func EncodeDictionary(values map[string]interface{}) string {
 	var builder strings.Builder
 	builder.Grow(estimateDictSize(values)) // Pre-allocate estimated size
-	builder.WriteByte('d')
+	if err := encodeDictionary(&builder, values); err != nil {
+		panic(err)
+	}
+	return builder.String()
+}
 
-	for key, value := range values {
-		writeStringToBuilder(&builder, key)
-		switch v := value.(type) {
-		case int:
-			writeIntegerToBuilder(&builder, v)
-		case string:
-			writeStringToBuilder(&builder, v)
-		case []any:
-			builder.WriteString(EncodeList(v))
-		default:
-			panic(fmt.Sprintf("unsupported type: %T", v))
+// Encode returns the bencoding of v. It covers every type parser.Parse
+// produces (int64, string, []any, map[string]any and parser.Dict) through a
+// fast path and falls back to Marshal's rules for anything else, so all
+// integer kinds, *big.Int, []byte, bool, structs and typed slices and maps
+// are accepted as well.
+// Values that cannot be encoded yield an *UnsupportedTypeError or
+// *UnsupportedValueError. To write large values without holding them in
+// memory, use an Encoder instead.
+func Encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeValue(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writer is the subset of strings.Builder and bytes.Buffer the encoder uses
+type writer interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// encodeValue handles the dynamic types produced by the parser without
+// reflection and hands everything else to marshalValue
+func encodeValue(w writer, value any) error {
+	switch v := value.(type) {
+	case string:
+		writeString(w, v)
+	case int64:
+		writeInteger(w, v)
+	case []any:
+		return encodeList(w, v)
+	case map[string]any:
+		return encodeDictionary(w, v)
+	case parser.Dict:
+		return encodeOrderedDictionary(w, v)
+	case int:
+		writeInteger(w, int64(v))
+	case int8:
+		writeInteger(w, int64(v))
+	case int16:
+		writeInteger(w, int64(v))
+	case int32:
+		writeInteger(w, int64(v))
+	case uint:
+		writeUnsigned(w, uint64(v))
+	case uint8:
+		writeUnsigned(w, uint64(v))
+	case uint16:
+		writeUnsigned(w, uint64(v))
+	case uint32:
+		writeUnsigned(w, uint64(v))
+	case uint64:
+		writeUnsigned(w, v)
+	case []byte:
+		writeBytes(w, v)
+	case *big.Int:
+		if v == nil {
+			return &UnsupportedValueError{Type: reflect.TypeOf(v), Str: "nil"}
 		}
+		writeBigInt(w, v)
+	case Marshaler:
+		// marshalValue rejects nil pointers before calling MarshalBencode
+		return marshalValue(w, reflect.ValueOf(v))
+	default:
+		return marshalValue(w, reflect.ValueOf(value))
 	}
+	return nil
+}
 
-	builder.WriteByte('e')
-	return builder.String()
+func encodeList(w writer, values []any) error {
+	w.WriteByte('l')
+	for _, value := range values {
+		if err := encodeValue(w, value); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('e')
+	return nil
 }
 
-// Helper functions for direct writing to builder (more efficient)
-func writeIntegerToBuilder(builder *strings.Builder, value int) {
-	builder.WriteByte('i')
-	builder.WriteString(strconv.Itoa(value))
-	builder.WriteByte('e')
+func encodeDictionary(w writer, values map[string]any) error {
+	w.WriteByte('d')
+	for _, key := range sortedKeys(values) {
+		writeString(w, key)
+		if err := encodeValue(w, values[key]); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('e')
+	return nil
 }
 
-func writeStringToBuilder(builder *strings.Builder, value string) {
-	lengthStr := strconv.Itoa(len(value))
-	builder.WriteString(lengthStr)
-	builder.WriteByte(':')
-	builder.WriteString(value)
+// encodeOrderedDictionary writes the entries of values in their stored
+// order, which need not be canonical
+func encodeOrderedDictionary(w writer, values parser.Dict) error {
+	w.WriteByte('d')
+	for _, entry := range values {
+		writeString(w, entry.Key)
+		if err := encodeValue(w, entry.Value); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('e')
+	return nil
+}
+
+// sortedKeys returns the keys of values in canonical bencode order. Go string
+// comparison is bytewise, which is exactly the ordering BEP 3 specifies.
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Helper functions for direct writing (more efficient than building
+// intermediate strings)
+func writeInteger(w writer, value int64) {
+	var digits [20]byte
+	w.WriteByte('i')
+	w.Write(strconv.AppendInt(digits[:0], value, 10))
+	w.WriteByte('e')
+}
+
+func writeUnsigned(w writer, value uint64) {
+	var digits [20]byte
+	w.WriteByte('i')
+	w.Write(strconv.AppendUint(digits[:0], value, 10))
+	w.WriteByte('e')
+}
+
+func writeBigInt(w writer, value *big.Int) {
+	w.WriteByte('i')
+	w.Write(value.Append(nil, 10))
+	w.WriteByte('e')
+}
+
+func writeString(w writer, value string) {
+	var digits [20]byte
+	w.Write(strconv.AppendInt(digits[:0], int64(len(value)), 10))
+	w.WriteByte(':')
+	w.WriteString(value)
+}
+
+func writeBytes(w writer, value []byte) {
+	var digits [20]byte
+	w.Write(strconv.AppendInt(digits[:0], int64(len(value)), 10))
+	w.WriteByte(':')
+	w.Write(value)
 }
 
 // Estimation functions for better memory pre-allocation
 func estimateListSize(values []interface{}) int {
 	estimate := 2 // 'l' and 'e'
 	for _, value := range values {
-		switch v := value.(type) {
-		case int:
-			estimate += 20 // Conservative estimate for integer encoding
-		case string:
-			estimate += len(strconv.Itoa(len(v))) + 1 + len(v) // length:string
-		case []any:
-			estimate += estimateListSize(v) // Recursive estimation
-		}
+		estimate += estimateValueSize(value)
 	}
 	return estimate
 }
@@ -107,14 +236,28 @@ func estimateDictSize(values map[string]interface{}) int {
 		estimate += len(strconv.Itoa(len(key))) + 1 + len(key)
 
 		// Value size
-		switch v := value.(type) {
-		case int:
-			estimate += 20 // Conservative estimate for integer encoding
-		case string:
-			estimate += len(strconv.Itoa(len(v))) + 1 + len(v)
-		case []any:
-			estimate += estimateListSize(v)
-		}
+		estimate += estimateValueSize(value)
 	}
 	return estimate
 }
+
+func estimateValueSize(value any) int {
+	switch v := value.(type) {
+	case string:
+		return len(strconv.Itoa(len(v))) + 1 + len(v) // length:string
+	case []byte:
+		return len(strconv.Itoa(len(v))) + 1 + len(v)
+	case []any:
+		return estimateListSize(v) // Recursive estimation
+	case map[string]any:
+		return estimateDictSize(v)
+	case parser.Dict:
+		estimate := 2
+		for _, entry := range v {
+			estimate += len(strconv.Itoa(len(entry.Key))) + 1 + len(entry.Key) + estimateValueSize(entry.Value)
+		}
+		return estimate
+	default:
+		return 20 // Conservative estimate for integer encoding
+	}
+}
@@ -2,8 +2,10 @@ package encoder
 
 import (
 	"fmt"
-	"strings"
+	"math/big"
 	"testing"
+
+	"github.com/kcabhinav/benparse/parser"
 )
 
 func TestEncodeInteger(t *testing.T) {
@@ -69,21 +71,73 @@ func TestEncodeDictionary(t *testing.T) {
 		t.Errorf("EncodeDictionary(%v) = %s; want %s", singleKeyDict, result, expected)
 	}
 
-	// Test multi-key dictionary (check components due to non-deterministic map iteration)
+	// Keys are emitted in sorted order regardless of map iteration order
 	multiKeyDict := map[string]any{
-		"key1": "value1",
-		"key2": "value2",
+		"key2":  "value2",
+		"key1":  "value1",
+		"Key3":  "value3",
+		"key10": "value10",
+	}
+	expected = "d4:Key36:value34:key16:value15:key107:value104:key26:value2e"
+	for i := 0; i < 10; i++ {
+		result = EncodeDictionary(multiKeyDict)
+		if result != expected {
+			t.Fatalf("EncodeDictionary(%v) = %s; want %s", multiKeyDict, result, expected)
+		}
+	}
+}
+
+func TestEncodeListNested(t *testing.T) {
+	// Values shaped like parser.Parse output used to panic with "unsupported type"
+	input := []any{int64(1), []byte("raw"), map[string]any{"b": int64(2), "a": []any{}}}
+	expected := "li1e3:rawd1:ale1:bi2eee"
+	if result := EncodeList(input); result != expected {
+		t.Errorf("EncodeList(%v) = %s; want %s", input, result, expected)
+	}
+
+	defer func() {
+		if _, ok := recover().(*UnsupportedTypeError); !ok {
+			t.Error("EncodeList with a float should panic with *UnsupportedTypeError")
+		}
+	}()
+	EncodeList([]any{1.5})
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{int64(-42), "i-42e"},
+		{int8(1), "i1e"},
+		{uint32(7), "i7e"},
+		{uint64(1 << 63), "i9223372036854775808e"},
+		{"spam", "4:spam"},
+		{[]byte{0, 1}, "2:\x00\x01"},
+		{[]any{int64(1), "a"}, "li1e1:ae"},
+		{
+			map[string]any{
+				"info":     map[string]any{"name": "test", "length": int64(5)},
+				"announce": "test.com",
+			},
+			"d8:announce8:test.com4:infod6:lengthi5e4:name4:testee",
+		},
+		{[]string{"typed", "slice"}, "l5:typed5:slicee"},
 	}
-	result = EncodeDictionary(multiKeyDict)
 
-	// Check that result starts with 'd' and ends with 'e'
-	if len(result) < 2 || result[0] != 'd' || result[len(result)-1] != 'e' {
-		t.Errorf("EncodeDictionary result should start with 'd' and end with 'e', got: %s", result)
+	for _, test := range tests {
+		result, err := Encode(test.input)
+		if err != nil {
+			t.Errorf("Encode(%v) unexpected error: %v", test.input, err)
+			continue
+		}
+		if string(result) != test.expected {
+			t.Errorf("Encode(%v) = %q; want %q", test.input, result, test.expected)
+		}
 	}
 
-	// Check that both key-value pairs are present
-	if !strings.Contains(result, "4:key16:value1") || !strings.Contains(result, "4:key26:value2") {
-		t.Errorf("EncodeDictionary(%v) = %s; should contain both key-value pairs", multiKeyDict, result)
+	if _, err := Encode(map[string]any{"bad": 1.5}); err == nil {
+		t.Error("Encode with a float value should return an error")
 	}
 }
 
@@ -172,3 +226,85 @@ func BenchmarkEncodeDictionaryLarge(b *testing.B) {
 		EncodeDictionary(largeDict)
 	}
 }
+
+func TestEncodeRawValue(t *testing.T) {
+	// Raw values are written verbatim, even when they are not canonical
+	info := parser.RawValue("d1:bi1e1:ai2ee")
+	input := map[string]any{"info": info, "announce": "test.com"}
+	expected := "d8:announce8:test.com4:infod1:bi1e1:ai2eee"
+
+	result, err := Encode(input)
+	if err != nil {
+		t.Fatalf("Encode(%v) unexpected error: %v", input, err)
+	}
+	if string(result) != expected {
+		t.Errorf("Encode(%v) = %s; want %s", input, result, expected)
+	}
+
+	result, err = Marshal(struct {
+		Info parser.RawValue `bencode:"info"`
+	}{info})
+	if err != nil || string(result) != "d4:infod1:bi1e1:ai2eee" {
+		t.Errorf("Marshal(RawValue field) = %s, %v; want %s", result, err, "d4:infod1:bi1e1:ai2eee")
+	}
+}
+
+func TestEncodeBigInt(t *testing.T) {
+	huge, _ := new(big.Int).SetString("-340282366920938463463374607431768211456", 10)
+	expected := "li-340282366920938463463374607431768211456ei5ee"
+
+	result, err := Encode([]any{huge, big.NewInt(5)})
+	if err != nil || string(result) != expected {
+		t.Errorf("Encode(big ints) = %s, %v; want %s", result, err, expected)
+	}
+
+	result, err = Marshal(struct {
+		A big.Int
+		B *big.Int
+	}{*big.NewInt(1), huge})
+	expected = "d1:Ai1e1:Bi-340282366920938463463374607431768211456ee"
+	if err != nil || string(result) != expected {
+		t.Errorf("Marshal(big int fields) = %s, %v; want %s", result, err, expected)
+	}
+
+	// Round-trip through the parser's big integer mode
+	decoded, err := parser.ParseWithOptions(string(result), parser.Options{Numbers: parser.NumberBigInt})
+	if err != nil {
+		t.Fatalf("ParseWithOptions(%s) unexpected error: %v", result, err)
+	}
+	reencoded, err := Encode(decoded)
+	if err != nil || string(reencoded) != expected {
+		t.Errorf("Encode(%v) = %s, %v; want %s", decoded, reencoded, err, expected)
+	}
+}
+
+func TestEncodeOrderedDict(t *testing.T) {
+	// Non-canonical key order and duplicate keys survive a round trip
+	inputs := []string{
+		"d3:fooi1e3:barl3:bazd1:zi1e1:ai2eee1:bi3ee",
+		"d1:bi1e1:ai2e1:bi3ee",
+		"d4:infod4:name4:test12:piece lengthi16eee",
+	}
+
+	for _, input := range inputs {
+		parsed, err := parser.ParseWithOptions(input, parser.Options{OrderedDicts: true})
+		if err != nil {
+			t.Fatalf("ParseWithOptions(%s) unexpected error: %v", input, err)
+		}
+
+		result, err := Encode(parsed)
+		if err != nil {
+			t.Fatalf("Encode(%v) unexpected error: %v", parsed, err)
+		}
+		if string(result) != input {
+			t.Errorf("Encode(%v) = %s; want %s", parsed, result, input)
+		}
+	}
+
+	result, err := Marshal(struct {
+		Info parser.Dict `bencode:"info"`
+	}{parser.Dict{{Key: "b", Value: 1}, {Key: "a", Value: 2}}})
+	if err != nil || string(result) != "d4:infod1:bi1e1:ai2eee" {
+		t.Errorf("Marshal(Dict field) = %s, %v; want %s", result, err, "d4:infod1:bi1e1:ai2eee")
+	}
+}
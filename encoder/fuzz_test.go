@@ -0,0 +1,59 @@
+package encoder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kcabhinav/benparse/parser"
+)
+
+func FuzzRoundTrip(f *testing.F) {
+	paths, _ := filepath.Glob("testdata/torrents/*.torrent")
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(data))
+	}
+	for _, seed := range []string{"i-42e", "4:spam", "le", "d1:bi1e1:ai2ee", "l01:ai+1ee"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		val, err := parser.Parse(input)
+		if err != nil {
+			return
+		}
+
+		encoded, err := Encode(val)
+		if err != nil {
+			t.Fatalf("%q: Encode(%v) failed: %v", input, val, err)
+		}
+		reparsed, err := parser.ParseStrict(string(encoded))
+		if err != nil {
+			t.Fatalf("%q: encoded %q is not canonical: %v", input, encoded, err)
+		}
+		if !reflect.DeepEqual(reparsed, val) {
+			t.Fatalf("%q: round trip changed %v into %v", input, val, reparsed)
+		}
+		if _, err := parser.ParseStrict(input); err == nil && string(encoded) != input {
+			t.Fatalf("%q: canonical input re-encoded as %q", input, encoded)
+		}
+
+		ordered, err := parser.ParseWithOptions(input, parser.Options{OrderedDicts: true})
+		if err != nil {
+			t.Fatalf("%q: OrderedDicts rejected input Parse accepted: %v", input, err)
+		}
+		encoded, err = Encode(ordered)
+		if err != nil {
+			t.Fatalf("%q: Encode(%v) failed: %v", input, ordered, err)
+		}
+		reordered, err := parser.ParseWithOptions(string(encoded), parser.Options{OrderedDicts: true})
+		if err != nil || !reflect.DeepEqual(reordered, ordered) {
+			t.Fatalf("%q: ordered round trip changed %v into %v, %v", input, ordered, reordered, err)
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package encoder
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kcabhinav/benparse/parser"
+)
+
+// UnsupportedTypeError is returned when a value's type has no bencode form
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type: %s", e.Type)
+}
+
+// UnsupportedValueError is returned for values of a supported type that still
+// cannot be encoded, such as nil pointers outside an omitempty field
+type UnsupportedValueError struct {
+	Type reflect.Type
+	Str  string
+}
+
+func (e *UnsupportedValueError) Error() string {
+	if e.Type == nil {
+		return "unsupported value: " + e.Str
+	}
+	return fmt.Sprintf("unsupported value: %s of type %s", e.Str, e.Type)
+}
+
+// Marshaler is implemented by types that produce their own bencoding, such
+// as parser.RawValue. MarshalBencode must return a single valid value.
+type Marshaler interface {
+	MarshalBencode() ([]byte, error)
+}
+
+var (
+	marshalerType     = reflect.TypeFor[Marshaler]()
+	bigIntType        = reflect.TypeFor[big.Int]()
+	bigIntPointerType = reflect.TypeFor[*big.Int]()
+	orderedDictType   = reflect.TypeFor[parser.Dict]()
+)
+
+// Marshal returns the bencoding of v.
+//
+// Integers of every width, including big.Int, encode as bencode integers and
+// booleans as i1e or i0e. Strings, byte slices and byte arrays encode as bencode strings, other
+// slices and arrays as lists. Maps with string keys and structs encode as
+// dictionaries with their keys in sorted order. Struct fields are named by
+// their `bencode:"name"` tag, or the field name when untagged; fields tagged
+// "-" are skipped and "omitempty" drops zero values. Of several fields with
+// the same name only a sole tagged one is encoded. Pointers and interfaces
+// encode as the value they point to and must not be nil. Values implementing
+// Marshaler are written as returned by MarshalBencode, and a parser.Dict
+// keeps its entries in their stored order.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := marshalValue(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func marshalValue(w writer, v reflect.Value) error {
+	if !v.IsValid() {
+		return &UnsupportedValueError{Type: nil, Str: "nil"}
+	}
+	if v.Type().Implements(marshalerType) {
+		// A nil pointer or interface has no MarshalBencode to call
+		if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+			return &UnsupportedValueError{Type: v.Type(), Str: "nil"}
+		}
+		return writeMarshaler(w, v.Interface().(Marshaler))
+	}
+
+	// big.Int is a struct, so it needs handling before the kind switch
+	switch {
+	case v.Type() == bigIntPointerType && !v.IsNil():
+		writeBigInt(w, v.Interface().(*big.Int))
+		return nil
+	case v.Type() == bigIntType:
+		n := v.Interface().(big.Int)
+		writeBigInt(w, &n)
+		return nil
+	case v.Type() == orderedDictType:
+		return encodeOrderedDictionary(w, v.Interface().(parser.Dict))
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			w.WriteString("i1e")
+		} else {
+			w.WriteString("i0e")
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeInteger(w, v.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUnsigned(w, v.Uint())
+
+	case reflect.String:
+		writeString(w, v.String())
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			writeBytes(w, v.Bytes())
+			return nil
+		}
+		return marshalList(w, v)
+
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			writeBytes(w, b)
+			return nil
+		}
+		return marshalList(w, v)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return &UnsupportedTypeError{v.Type()}
+		}
+		return marshalMap(w, v)
+
+	case reflect.Struct:
+		return marshalStruct(w, v)
+
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return &UnsupportedValueError{Type: v.Type(), Str: "nil"}
+		}
+		return marshalValue(w, v.Elem())
+
+	default:
+		return &UnsupportedTypeError{v.Type()}
+	}
+	return nil
+}
+
+func writeMarshaler(w writer, m Marshaler) error {
+	b, err := m.MarshalBencode()
+	if err != nil {
+		return err
+	}
+	w.Write(b)
+	return nil
+}
+
+func marshalList(w writer, v reflect.Value) error {
+	w.WriteByte('l')
+	for i := 0; i < v.Len(); i++ {
+		if err := marshalValue(w, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('e')
+	return nil
+}
+
+func marshalMap(w writer, v reflect.Value) error {
+	// Bencode requires dictionary keys sorted as raw byte strings
+	keys := make([]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+
+	w.WriteByte('d')
+	for _, key := range keys {
+		writeString(w, key)
+		value := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+		if err := marshalValue(w, value); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('e')
+	return nil
+}
+
+func marshalStruct(w writer, v reflect.Value) error {
+	w.WriteByte('d')
+	for _, f := range cachedFields(v.Type()) {
+		value := v.Field(f.index)
+		if f.omitEmpty && isEmptyValue(value) {
+			continue
+		}
+		writeString(w, f.name)
+		if err := marshalValue(w, value); err != nil {
+			return err
+		}
+	}
+	w.WriteByte('e')
+	return nil
+}
+
+// isEmptyValue reports whether v is dropped by an omitempty field
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// field describes how a struct field is encoded
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+	tagged    bool // the name comes from a bencode tag
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// cachedFields returns the encoded fields of struct type t sorted by key.
+// Like encoding/json, when several fields share a key the only tagged one
+// is encoded, and if there is none or more than one all of them are dropped.
+func cachedFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+
+	fields := make([]field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("bencode"), ",")
+		if name == "-" {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: i, omitEmpty: slices.Contains(strings.Split(opts, ","), "omitempty"), tagged: tagged})
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+
+	unique := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+		if f, ok := dominantField(fields[i:j]); ok {
+			unique = append(unique, f)
+		}
+		i = j
+	}
+	fields = unique
+
+	f, _ := fieldCache.LoadOrStore(t, fields)
+	return f.([]field)
+}
+
+// dominantField picks the field to encode among fields sharing a key
+func dominantField(fields []field) (field, bool) {
+	if len(fields) == 1 {
+		return fields[0], true
+	}
+	var tagged []field
+	for _, f := range fields {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) != 1 {
+		return field{}, false
+	}
+	return tagged[0], true
+}
@@ -0,0 +1,118 @@
+package encoder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kcabhinav/benparse/parser"
+)
+
+type testFile struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+}
+
+type testInfo struct {
+	Name        string     `bencode:"name"`
+	PieceLength uint32     `bencode:"piece length"`
+	Pieces      []byte     `bencode:"pieces"`
+	Files       []testFile `bencode:"files,omitempty"`
+	Private     bool       `bencode:"private,omitempty"`
+}
+
+type testTorrent struct {
+	Info     testInfo `bencode:"info"`
+	Announce string   `bencode:"announce"`
+	Comment  *string  `bencode:"comment,omitempty"`
+	Ignored  string   `bencode:"-"`
+	Hash     [4]byte
+	internal int
+}
+
+func TestMarshal(t *testing.T) {
+	comment := "hi"
+	tests := []struct {
+		name     string
+		input    any
+		expected string
+	}{
+		{"int8", int8(-5), "i-5e"},
+		{"uint64", uint64(18446744073709551615), "i18446744073709551615e"},
+		{"bool", true, "i1e"},
+		{"string", "spam", "4:spam"},
+		{"bytes", []byte("raw"), "3:raw"},
+		{"list", []any{1, "a", []int{2}}, "li1e1:ali2eee"},
+		{"array", [2]int16{3, 4}, "li3ei4ee"},
+		{"nil slice", []int(nil), "le"},
+		{"sorted map", map[string]int{"b": 2, "a": 1, "c": 3}, "d1:ai1e1:bi2e1:ci3ee"},
+		{"pointer", &comment, "2:hi"},
+		{
+			"struct",
+			testTorrent{
+				Info: testInfo{
+					Name:        "test",
+					PieceLength: 16,
+					Pieces:      []byte("abcd"),
+					Files:       []testFile{{Length: 1, Path: []string{"a"}}},
+				},
+				Announce: "test.com",
+				Ignored:  "skip",
+				Hash:     [4]byte{'w', 'x', 'y', 'z'},
+				internal: 1,
+			},
+			"d4:Hash4:wxyz8:announce8:test.com4:infod5:filesld6:lengthi1e4:pathl1:aeee" +
+				"4:name4:test12:piece lengthi16e6:pieces4:abcdee",
+		},
+		{
+			"ambiguous keys",
+			struct {
+				A int `bencode:"b"`
+				B int `bencode:"b"`
+				C int
+				D int `bencode:"C"`
+			}{1, 2, 3, 4},
+			"d1:Ci4ee",
+		},
+	}
+
+	for _, test := range tests {
+		result, err := Marshal(test.input)
+		if err != nil {
+			t.Errorf("Marshal(%s) unexpected error: %v", test.name, err)
+			continue
+		}
+		if string(result) != test.expected {
+			t.Errorf("Marshal(%s) = %s; want %s", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	var nilPointer *int
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{"float", 1.5},
+		{"int keys", map[int]string{1: "a"}},
+		{"channel", []any{make(chan int)}},
+		{"nil", nil},
+		{"nil pointer", nilPointer},
+		{"nil in list", []any{1, nil}},
+		{"nil Marshaler interface", struct{ M Marshaler }{}},
+		{"nil Marshaler pointer", (*parser.RawValue)(nil)},
+		{"nil Marshaler pointer in list", []any{(*parser.RawValue)(nil)}},
+	}
+
+	for _, test := range tests {
+		_, err := Marshal(test.input)
+		var typeErr *UnsupportedTypeError
+		var valueErr *UnsupportedValueError
+		if !errors.As(err, &typeErr) && !errors.As(err, &valueErr) {
+			t.Errorf("Marshal(%s) error = %v; want unsupported type or value error", test.name, err)
+		}
+		if _, err := Encode(test.input); !errors.As(err, &typeErr) && !errors.As(err, &valueErr) {
+			t.Errorf("Encode(%s) error = %v; want unsupported type or value error", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package encoder
+
+import (
+	"math/big"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/kcabhinav/benparse/parser"
+)
+
+// The corpus mixes real torrents for freely redistributable content with
+// synthetic ones. The real ones are the Debian 10.8.0 and Arch Linux
+// 2011.08.19 install images, the Blender Foundation's Sintel (CC BY 3.0) as
+// published by WebTorrent and the WIRED CD (Creative Commons Sampling Plus),
+// taken from the testdata of github.com/anacrolix/torrent. The synthetic-*
+// torrents cover what those lack: private, DHT node and hybrid v1/v2
+// torrents and edge case values, with made-up names, trackers and hashes.
+func TestRoundTripCorpus(t *testing.T) {
+	paths, err := filepath.Glob("testdata/torrents/*.torrent")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("No corpus files found: %v", err)
+	}
+
+	modes := []struct {
+		name string
+		opts parser.Options
+	}{
+		{"default", parser.Options{}},
+		{"big integers", parser.Options{Numbers: parser.NumberBigInt}},
+		{"ordered dictionaries", parser.Options{OrderedDicts: true}},
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Reading %s: %v", path, err)
+		}
+		if _, err := parser.ParseStrict(string(data)); err != nil {
+			t.Fatalf("%s is not canonical: %v", path, err)
+		}
+
+		for _, mode := range modes {
+			t.Run("Testing "+filepath.Base(path)+" with "+mode.name, func(t *testing.T) {
+				parsed, err := parser.ParseWithOptions(string(data), mode.opts)
+				if err != nil {
+					t.Fatalf("Unexpected parse error: %v", err)
+				}
+				result, err := Encode(parsed)
+				if err != nil {
+					t.Fatalf("Unexpected encode error: %v", err)
+				}
+				if string(result) != string(data) {
+					t.Errorf("Round trip changed %d bytes into %d bytes", len(data), len(result))
+				}
+			})
+		}
+	}
+}
+
+// randomValue is a value of the types Parse produces, generated by testing/quick
+type randomValue struct {
+	v any
+}
+
+func (randomValue) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(randomValue{generateValue(r, size, 4)})
+}
+
+// generateValue returns a random int64, string, []any or map[string]any,
+// nesting at most depth containers
+func generateValue(r *rand.Rand, size, depth int) any {
+	kind := r.Intn(4)
+	if depth == 0 {
+		kind = r.Intn(2)
+	}
+
+	switch kind {
+	case 0:
+		return r.Int63() - r.Int63()
+	case 1:
+		return generateString(r, size)
+	case 2:
+		list := make([]any, r.Intn(size+1))
+		for i := range list {
+			list[i] = generateValue(r, size/2, depth-1)
+		}
+		return list
+	default:
+		dict := make(map[string]any)
+		for n := r.Intn(size + 1); n > 0; n-- {
+			dict[generateString(r, 8)] = generateValue(r, size/2, depth-1)
+		}
+		return dict
+	}
+}
+
+// generateString returns a string of random bytes, not necessarily UTF-8
+func generateString(r *rand.Rand, size int) string {
+	b := make([]byte, r.Intn(size+1))
+	r.Read(b)
+	return string(b)
+}
+
+func TestRoundTripProperties(t *testing.T) {
+	t.Run("Testing parse inverts encode", func(t *testing.T) {
+		property := func(value randomValue) bool {
+			encoded, err := Encode(value.v)
+			if err != nil {
+				return false
+			}
+			parsed, err := parser.Parse(string(encoded))
+			return err == nil && reflect.DeepEqual(parsed, value.v)
+		}
+		if err := quick.Check(property, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Testing encode inverts parse on canonical input", func(t *testing.T) {
+		property := func(value randomValue) bool {
+			canonical, err := Encode(value.v)
+			if err != nil {
+				return false
+			}
+			if _, err := parser.ParseStrict(string(canonical)); err != nil {
+				return false
+			}
+			parsed, err := parser.Parse(string(canonical))
+			if err != nil {
+				return false
+			}
+			result, err := Encode(parsed)
+			return err == nil && string(result) == string(canonical)
+		}
+		if err := quick.Check(property, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("Testing integers beyond int64", func(t *testing.T) {
+		property := func(hi, lo int64) bool {
+			n := new(big.Int).Lsh(big.NewInt(hi), 64)
+			n.Add(n, big.NewInt(lo))
+			encoded, err := Encode(n)
+			if err != nil {
+				return false
+			}
+			parsed, err := parser.ParseWithOptions(string(encoded), parser.Options{Numbers: parser.NumberBigInt})
+			if err != nil {
+				return false
+			}
+			result, err := Encode(parsed)
+			return err == nil && string(result) == string(encoded)
+		}
+		if err := quick.Check(property, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
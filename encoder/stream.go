@@ -0,0 +1,160 @@
+package encoder
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// Errors returned when the incremental Encoder methods are called out of order
+var (
+	ErrKeyOutsideDict  = errors.New("key written outside a dictionary")
+	ErrMissingKey      = errors.New("dictionary value written without a key")
+	ErrMissingValue    = errors.New("dictionary key has no value")
+	ErrUnsortedKey     = errors.New("dictionary keys not in sorted order")
+	ErrNoOpenContainer = errors.New("end written without an open list or dictionary")
+)
+
+// Encoder writes bencoded values to an output stream.
+//
+// Encode writes a whole value. Large values can instead be written piece by
+// piece with BeginDict, BeginList, Key and End, which never hold more than
+// the encoder's buffer in memory:
+//
+//	enc.BeginDict()
+//	enc.Key("announce")
+//	enc.Encode(announce)
+//	enc.Key("info")
+//	enc.Encode(info)
+//	enc.End()
+//
+// Output is buffered and flushed each time a top-level value is complete.
+// The first error is sticky: once a call fails, every later call returns the
+// same error.
+type Encoder struct {
+	w     *bufio.Writer
+	stack []container // open lists and dictionaries, innermost last
+	err   error
+}
+
+// container tracks an open list or dictionary
+type container struct {
+	dict    bool
+	hasKey  bool   // a key has been written and awaits its value
+	started bool   // at least one key has been written
+	lastKey string // previous key, for the ordering check
+}
+
+// NewEncoder returns an encoder that writes to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes the bencoding of v, accepting the same values as the
+// package-level Encode. Inside a dictionary it writes the value of the
+// preceding Key.
+func (enc *Encoder) Encode(v any) error {
+	if err := enc.beginValue(); err != nil {
+		return err
+	}
+	if err := encodeValue(enc.w, v); err != nil {
+		enc.err = err
+		return err
+	}
+	return enc.endValue()
+}
+
+// BeginDict starts a dictionary. Its entries are written with Key followed
+// by a value, in sorted key order, and it is closed with End.
+func (enc *Encoder) BeginDict() error {
+	return enc.begin('d', container{dict: true})
+}
+
+// BeginList starts a list, closed with End
+func (enc *Encoder) BeginList() error {
+	return enc.begin('l', container{})
+}
+
+// Key writes the next key of the innermost dictionary. Keys must be written
+// in strictly increasing byte order so that the output stays canonical.
+func (enc *Encoder) Key(key string) error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	if len(enc.stack) == 0 || !enc.stack[len(enc.stack)-1].dict {
+		return enc.fail(ErrKeyOutsideDict)
+	}
+	top := &enc.stack[len(enc.stack)-1]
+	if top.hasKey {
+		return enc.fail(ErrMissingValue)
+	}
+	if top.started && key <= top.lastKey {
+		return enc.fail(ErrUnsortedKey)
+	}
+
+	writeString(enc.w, key)
+	top.hasKey = true
+	top.started = true
+	top.lastKey = key
+	return nil
+}
+
+// End closes the innermost list or dictionary
+func (enc *Encoder) End() error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	if len(enc.stack) == 0 {
+		return enc.fail(ErrNoOpenContainer)
+	}
+	if enc.stack[len(enc.stack)-1].hasKey {
+		return enc.fail(ErrMissingValue)
+	}
+
+	enc.stack = enc.stack[:len(enc.stack)-1]
+	enc.w.WriteByte('e')
+	return enc.endValue()
+}
+
+func (enc *Encoder) begin(c byte, state container) error {
+	if err := enc.beginValue(); err != nil {
+		return err
+	}
+	enc.w.WriteByte(c)
+	enc.stack = append(enc.stack, state)
+	return nil
+}
+
+// beginValue checks that a value may be written at the current position
+func (enc *Encoder) beginValue() error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	if len(enc.stack) > 0 {
+		top := &enc.stack[len(enc.stack)-1]
+		if top.dict && !top.hasKey {
+			return enc.fail(ErrMissingKey)
+		}
+		top.hasKey = false
+	}
+	return nil
+}
+
+// endValue flushes the output once a top-level value is complete
+func (enc *Encoder) endValue() error {
+	if len(enc.stack) > 0 {
+		return nil
+	}
+	if err := enc.w.Flush(); err != nil {
+		return enc.fail(err)
+	}
+	return nil
+}
+
+func (enc *Encoder) fail(err error) error {
+	enc.err = err
+	return err
+}
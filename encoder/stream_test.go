@@ -0,0 +1,134 @@
+package encoder
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingWriter returns err from every write
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestEncoder(t *testing.T) {
+	t.Run("Testing Encode writes consecutive values", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+
+		for _, v := range []any{int64(42), "spam", map[string]any{"b": 2, "a": []any{"x"}}} {
+			if err := enc.Encode(v); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		expected := "i42e4:spamd1:al1:xe1:bi2ee"
+		if buf.String() != expected {
+			t.Errorf("Got %v Wanted %v", buf.String(), expected)
+		}
+	})
+
+	t.Run("Testing incremental dictionary", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+
+		pieces := strings.Repeat("x", 100000)
+		steps := []func() error{
+			enc.BeginDict,
+			func() error { return enc.Key("announce") },
+			func() error { return enc.Encode("test.com") },
+			func() error { return enc.Key("info") },
+			enc.BeginDict,
+			func() error { return enc.Key("files") },
+			enc.BeginList,
+			func() error { return enc.Encode(testFile{Length: 1, Path: []string{"a"}}) },
+			enc.End,
+			func() error { return enc.Key("pieces") },
+			func() error { return enc.Encode(pieces) },
+			enc.End,
+			enc.End,
+		}
+		for i, step := range steps {
+			if err := step(); err != nil {
+				t.Fatalf("Step %d: unexpected error: %v", i, err)
+			}
+			if i < len(steps)-1 && buf.Len() > 0 && buf.Len() < 100000 {
+				t.Fatalf("Step %d: output flushed before the value was complete", i)
+			}
+		}
+
+		expected := "d8:announce8:test.com4:infod5:filesld6:lengthi1e4:pathl1:aeee6:pieces100000:" + pieces + "ee"
+		if buf.String() != expected {
+			t.Errorf("Got %d bytes Wanted %d bytes", buf.Len(), len(expected))
+		}
+	})
+
+	t.Run("Testing misuse is rejected", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			steps    func(enc *Encoder) error
+			expected error
+		}{
+			{"key outside dictionary", func(enc *Encoder) error {
+				enc.BeginList()
+				return enc.Key("a")
+			}, ErrKeyOutsideDict},
+			{"value without key", func(enc *Encoder) error {
+				enc.BeginDict()
+				return enc.Encode(1)
+			}, ErrMissingKey},
+			{"key without value", func(enc *Encoder) error {
+				enc.BeginDict()
+				enc.Key("a")
+				return enc.End()
+			}, ErrMissingValue},
+			{"unsorted keys", func(enc *Encoder) error {
+				enc.BeginDict()
+				enc.Key("b")
+				enc.Encode(1)
+				return enc.Key("a")
+			}, ErrUnsortedKey},
+			{"duplicate keys", func(enc *Encoder) error {
+				enc.BeginDict()
+				enc.Key("a")
+				enc.Encode(1)
+				return enc.Key("a")
+			}, ErrUnsortedKey},
+			{"unbalanced end", func(enc *Encoder) error {
+				return enc.End()
+			}, ErrNoOpenContainer},
+		}
+
+		for _, test := range tests {
+			enc := NewEncoder(&bytes.Buffer{})
+			err := test.steps(enc)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("%s: Got %v Wanted %v", test.name, err, test.expected)
+			}
+			if err := enc.Encode(1); !errors.Is(err, test.expected) {
+				t.Errorf("%s: error not sticky, got %v", test.name, err)
+			}
+		}
+	})
+
+	t.Run("Testing unsupported values", func(t *testing.T) {
+		enc := NewEncoder(&bytes.Buffer{})
+		var unsupported *UnsupportedTypeError
+		if err := enc.Encode(1.5); !errors.As(err, &unsupported) {
+			t.Errorf("Got %v Wanted *UnsupportedTypeError", err)
+		}
+	})
+
+	t.Run("Testing write errors", func(t *testing.T) {
+		writeErr := errors.New("disk full")
+		enc := NewEncoder(failingWriter{writeErr})
+		if err := enc.Encode("spam"); !errors.Is(err, writeErr) {
+			t.Errorf("Got %v Wanted %v", err, writeErr)
+		}
+	})
+}
@@ -1,46 +1,61 @@
 package main
 
 import (
-	parser "benparse/parser"
 	"fmt"
 	"os"
-	"reflect"
+	"path/filepath"
+
+	"github.com/kcabhinav/benparse/metainfo"
 )
 
 func main() {
-	filePath := "/home/kcx/Downloads/ubuntu-25.04-desktop-amd64.iso.torrent"
-
-	// Read the file content
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		fmt.Println("Error reading file:", err)
-		return
+	if len(os.Args) != 2 {
+		fmt.Println("Usage: benparse <file.torrent>")
+		os.Exit(2)
 	}
 
-	// Parse the content
-	val, err := parser.Parse(string(content))
+	// Read and validate the torrent
+	m, err := metainfo.Load(os.Args[1])
 	if err != nil {
 		fmt.Println("Error:", err)
-		return
+		os.Exit(1)
 	}
 
-	printMapKeys(val)
-
+	printMetainfo(m)
 }
 
-func printMapKeys(input any) {
-	val := reflect.ValueOf(input)
-	typ := val.Type()
+func printMetainfo(m *metainfo.Metainfo) {
+	fmt.Println("Name:         ", m.Info.Name)
+	if m.Info.IsV1() {
+		fmt.Println("Info hash:    ", m.InfoHashV1())
+	}
+	if m.Info.IsV2() {
+		fmt.Println("Info hash v2: ", m.InfoHashV2())
+	}
+	for i, tier := range m.Trackers() {
+		fmt.Printf("Tracker tier %d: %v\n", i, tier)
+	}
+	if created := m.CreationTime(); !created.IsZero() {
+		fmt.Println("Created:      ", created)
+	}
+	if m.CreatedBy != "" {
+		fmt.Println("Created by:   ", m.CreatedBy)
+	}
+	if m.Comment != "" {
+		fmt.Println("Comment:      ", m.Comment)
+	}
+	fmt.Println("Piece length: ", m.Info.PieceLength)
+	if m.Info.IsV1() {
+		fmt.Println("Pieces:       ", m.Info.NumPieces())
+	}
+	fmt.Println("Total length: ", m.Info.TotalLength())
+	fmt.Println("Private:      ", m.Info.Private)
 
-	if val.Kind() != reflect.Map {
-		fmt.Println("Not a map")
+	if !m.Info.IsMultiFile() {
 		return
 	}
-
-	fmt.Printf("Map of type: %s\n", typ)
-	fmt.Println("Keys:")
-
-	for _, key := range val.MapKeys() {
-		fmt.Println(key.Interface())
+	fmt.Println("Files:")
+	for _, f := range m.Info.FileEntries() {
+		fmt.Printf("  %12d  %s\n", f.Length, filepath.Join(f.Path...))
 	}
 }
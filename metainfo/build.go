@@ -0,0 +1,283 @@
+package metainfo
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kcabhinav/benparse/encoder"
+	"github.com/kcabhinav/benparse/parser"
+)
+
+// Bounds of the piece length Build selects, which aims for at most
+// targetPieces pieces
+const (
+	minPieceLength = 16 << 10
+	maxPieceLength = 16 << 20
+	targetPieces   = 1500
+)
+
+// BuildOptions configures Build
+type BuildOptions struct {
+	Name         string // defaults to the base name of the path
+	PieceLength  int64  // defaults to DefaultPieceLength of the total size
+	Announce     string // defaults to the first tracker of AnnounceList
+	AnnounceList [][]string
+	Comment      string
+	CreatedBy    string
+	CreationDate time.Time // left out when zero
+	Private      bool
+	Workers      int // goroutines hashing pieces, defaults to GOMAXPROCS
+}
+
+// DefaultPieceLength returns the piece length Build uses for content of
+// total bytes: the smallest power of two from 16 KiB to 16 MiB that keeps
+// the torrent to about 1500 pieces
+func DefaultPieceLength(total int64) int64 {
+	pieceLength := int64(minPieceLength)
+	for pieceLength < maxPieceLength && total/pieceLength > targetPieces {
+		pieceLength *= 2
+	}
+	return pieceLength
+}
+
+// Build creates a torrent of the file or directory at path, hashing its
+// pieces concurrently. A directory contributes its regular files in lexical
+// order of their paths. InfoBytes is set to the canonical encoding of Info.
+func Build(path string, opts BuildOptions) (*Metainfo, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := Info{Name: opts.Name, PieceLength: opts.PieceLength, Private: opts.Private}
+	if info.Name == "" {
+		info.Name = filepath.Base(path)
+	}
+	if !isPathComponent(info.Name) {
+		return nil, fmt.Errorf("invalid torrent name %q", info.Name)
+	}
+
+	var sources []source
+	if stat.IsDir() {
+		if sources, err = collectFiles(path); err != nil {
+			return nil, err
+		}
+		info.Files = make([]FileEntry, len(sources))
+		for i, s := range sources {
+			info.Files[i] = s.entry
+		}
+	} else {
+		info.Length = stat.Size()
+		sources = []source{{path: path, entry: FileEntry{Length: info.Length}}}
+	}
+
+	total := info.TotalLength()
+	if total == 0 {
+		return nil, fmt.Errorf("%s: no data to hash", path)
+	}
+	if info.PieceLength == 0 {
+		info.PieceLength = DefaultPieceLength(total)
+	} else if info.PieceLength < 0 {
+		return nil, fmt.Errorf("piece length %d is not positive", info.PieceLength)
+	} else if info.PieceLength > pieceLengthLimit {
+		return nil, fmt.Errorf("piece length %d exceeds %d", info.PieceLength, pieceLengthLimit)
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if info.Pieces, err = hashPieces(sources, total, info.PieceLength, workers); err != nil {
+		return nil, err
+	}
+
+	m := &Metainfo{
+		Announce:     opts.Announce,
+		AnnounceList: opts.AnnounceList,
+		Comment:      opts.Comment,
+		CreatedBy:    opts.CreatedBy,
+		Info:         info,
+	}
+	if m.Announce == "" && len(m.AnnounceList) > 0 && len(m.AnnounceList[0]) > 0 {
+		// Clients without announce-list support still find a tracker
+		m.Announce = m.AnnounceList[0][0]
+	}
+	if !opts.CreationDate.IsZero() {
+		m.CreationDate = opts.CreationDate.Unix()
+	}
+	infoBytes, err := encoder.Marshal(m.Info)
+	if err != nil {
+		return nil, err
+	}
+	m.InfoBytes = parser.RawValue(infoBytes)
+	return m, nil
+}
+
+// Write writes the torrent as bencode. The info dictionary is written as
+// InfoBytes when it is set, so that a loaded torrent keeps its info-hash.
+func (m *Metainfo) Write(w io.Writer) error {
+	data, err := encoder.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if len(m.InfoBytes) > 0 {
+		top, err := keysOf(parser.RawValue(data))
+		if err != nil {
+			return err
+		}
+		top["info"] = m.InfoBytes
+		if data, err = encoder.Marshal(top); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// source is a file on disk holding one entry of a torrent
+type source struct {
+	path  string
+	entry FileEntry
+}
+
+// collectFiles returns the regular files below dir in lexical order
+func collectFiles(dir string) ([]source, error) {
+	var sources []source
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		components := strings.Split(filepath.ToSlash(rel), "/")
+		for _, c := range components {
+			if !isPathComponent(c) {
+				return fmt.Errorf("%s: invalid path component %q", path, c)
+			}
+		}
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source{path: path, entry: FileEntry{Length: stat.Size(), Path: components}})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%s: no files", dir)
+	}
+	return sources, nil
+}
+
+// hashPieces returns the concatenated SHA-1 hashes of the pieces of sources,
+// which hold total bytes. Pieces are read in order and hashed by workers,
+// which hand their buffers back for reuse so that at most one piece per
+// worker is held in memory.
+func hashPieces(sources []source, total, pieceLength int64, workers int) ([]byte, error) {
+	type piece struct {
+		index int
+		data  []byte
+	}
+	numPieces := int((total + pieceLength - 1) / pieceLength)
+	pieces := make([]byte, numPieces*PieceHashSize)
+	queue := make(chan piece, workers)
+	free := make(chan []byte, workers)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for p := range queue {
+				h := sha1.Sum(p.data)
+				copy(pieces[p.index*PieceHashSize:], h[:])
+				free <- p.data
+			}
+		}()
+	}
+
+	readers := make([]io.Reader, len(sources))
+	for i := range sources {
+		readers[i] = &sourceReader{source: &sources[i], remaining: sources[i].entry.Length}
+	}
+	defer func() {
+		for _, r := range readers {
+			r.(*sourceReader).close()
+		}
+	}()
+
+	r := io.MultiReader(readers...)
+	var err error
+	for i := range numPieces {
+		var buf []byte
+		if i < workers {
+			buf = make([]byte, min(pieceLength, total))
+		} else {
+			buf = <-free
+		}
+		data := buf[:min(pieceLength, total-int64(i)*pieceLength)]
+		if _, err = io.ReadFull(r, data); err != nil {
+			break
+		}
+		queue <- piece{index: i, data: data}
+	}
+	close(queue)
+	wg.Wait()
+	return pieces, err
+}
+
+// sourceReader reads the bytes of a source measured by Build, opening the
+// file on first use and closing it once they are read
+type sourceReader struct {
+	*source
+	remaining int64
+	file      *os.File
+}
+
+func (r *sourceReader) Read(p []byte) (int, error) {
+	if r.remaining == 0 {
+		r.close()
+		return 0, io.EOF
+	}
+	if r.file == nil {
+		file, err := os.Open(r.path)
+		if err != nil {
+			return 0, err
+		}
+		r.file = file
+	}
+
+	n, err := r.file.Read(p[:min(int64(len(p)), r.remaining)])
+	r.remaining -= int64(n)
+	if errors.Is(err, io.EOF) {
+		if n > 0 {
+			return n, nil
+		}
+		return 0, fmt.Errorf("%s: file shrank while hashing", r.path)
+	}
+	return n, err
+}
+
+func (r *sourceReader) close() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+}
@@ -0,0 +1,155 @@
+package metainfo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeContent writes length bytes of the pattern the testdata torrents were
+// generated from to path
+func writeContent(t *testing.T, path string, length, seed int) {
+	t.Helper()
+	data := make([]byte, length)
+	for i := range data {
+		data[i] = byte((i*seed + 7) % 251)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeMultiContent writes the content of testdata/multi.torrent below dir
+func writeMultiContent(t *testing.T, dir string) {
+	t.Helper()
+	writeContent(t, filepath.Join(dir, "a.txt"), 50000, 5)
+	writeContent(t, filepath.Join(dir, "dir", "b.bin"), 70000, 11)
+	writeContent(t, filepath.Join(dir, "dir", "empty"), 0, 1)
+}
+
+func TestBuild(t *testing.T) {
+	t.Run("Testing multi-file torrent", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "multi")
+		writeMultiContent(t, dir)
+
+		m, err := Build(dir, BuildOptions{
+			PieceLength: 32768,
+			AnnounceList: [][]string{
+				{"http://tracker.example.com/announce"},
+				{"udp://backup.example.com:6969/announce"},
+			},
+			CreationDate: time.Unix(1700000001, 0),
+			Private:      true,
+			Workers:      3,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var out bytes.Buffer
+		if err := m.Write(&out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected, err := os.ReadFile("testdata/multi.torrent")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), expected) {
+			t.Errorf("Got %q Wanted %q", out.Bytes(), expected)
+		}
+	})
+
+	t.Run("Testing single-file torrent", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "data.bin")
+		writeContent(t, path, 100000, 7)
+
+		m, err := Build(path, BuildOptions{Name: "renamed.bin", Comment: "c", CreatedBy: "benparse"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if m.Info.Name != "renamed.bin" || m.Info.IsMultiFile() || m.Info.Length != 100000 || m.Info.PieceLength != 16<<10 {
+			t.Errorf("Got %+v", m.Info)
+		}
+		if m.Comment != "c" || m.CreatedBy != "benparse" || m.CreationDate != 0 || m.Announce != "" {
+			t.Errorf("Got %+v", m)
+		}
+
+		// Written torrents load back with the same info-hash
+		var out bytes.Buffer
+		if err := m.Write(&out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		loaded, err := Read(&out)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if loaded.InfoHashV1() != m.InfoHashV1() || loaded.Info.NumPieces() != 7 {
+			t.Errorf("Got %v Wanted %v", loaded.InfoHashV1(), m.InfoHashV1())
+		}
+	})
+
+	t.Run("Testing more pieces than workers", func(t *testing.T) {
+		// Workers hand their buffers back, so later pieces reuse them
+		root := t.TempDir()
+		writeContent(t, filepath.Join(root, "data.bin"), 300000, 13)
+
+		m, err := Build(filepath.Join(root, "data.bin"), BuildOptions{PieceLength: 16 << 10, Workers: 2})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		report, err := Verify(&m.Info, root, VerifyOptions{Workers: 1})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !report.OK() || m.Info.NumPieces() != 19 {
+			t.Errorf("Got bad pieces %v of %d", report.BadPieces(), m.Info.NumPieces())
+		}
+	})
+
+	t.Run("Testing errors", func(t *testing.T) {
+		empty := t.TempDir()
+		emptyFile := filepath.Join(t.TempDir(), "empty")
+		writeContent(t, emptyFile, 0, 1)
+
+		tests := []struct {
+			name string
+			path string
+			opts BuildOptions
+		}{
+			{"missing path", filepath.Join(empty, "missing"), BuildOptions{}},
+			{"empty directory", empty, BuildOptions{}},
+			{"empty file", emptyFile, BuildOptions{}},
+			{"unsafe name", emptyFile, BuildOptions{Name: ".."}},
+			{"negative piece length", "testdata", BuildOptions{PieceLength: -1}},
+			{"huge piece length", "testdata", BuildOptions{PieceLength: 1 << 50}},
+		}
+		for _, test := range tests {
+			if _, err := Build(test.path, test.opts); err == nil {
+				t.Errorf("%s: Error expected. Got nil", test.name)
+			}
+		}
+	})
+}
+
+func TestDefaultPieceLength(t *testing.T) {
+	tests := []struct {
+		total    int64
+		expected int64
+	}{
+		{1, 16 << 10},
+		{1500 * 16 << 10, 16 << 10},
+		{1500*16<<10 + 16<<10, 32 << 10},
+		{4 << 30, 4 << 20},
+		{1 << 50, 16 << 20},
+	}
+	for _, test := range tests {
+		if got := DefaultPieceLength(test.total); got != test.expected {
+			t.Errorf("DefaultPieceLength(%d): Got %d Wanted %d", test.total, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package metainfo
+
+import (
+	"crypto/sha256"
+	"maps"
+	"math/big"
+	"reflect"
+	"slices"
+
+	"github.com/kcabhinav/benparse/encoder"
+	"github.com/kcabhinav/benparse/parser"
+)
+
+// FileTree is a directory of a BEP 52 file tree, mapping each path component
+// to the file or directory it names
+type FileTree map[string]*FileTreeNode
+
+// FileTreeNode is a file or a directory of a file tree
+type FileTreeNode struct {
+	Length     int64    // of a file
+	PiecesRoot []byte   // merkle root of a file's blocks, empty for an empty file
+	Children   FileTree // of a directory, nil for a file
+}
+
+// TreeFile is a file of a file tree together with its path
+type TreeFile struct {
+	Path       []string // path components below the torrent's directory
+	Length     int64
+	PiecesRoot []byte
+}
+
+// fileAttributes is the dictionary stored under the empty key of a file node
+type fileAttributes struct {
+	Length     int64  `bencode:"length"`
+	PiecesRoot []byte `bencode:"pieces root,omitempty"`
+}
+
+// IsFile reports whether n is a file rather than a directory
+func (n *FileTreeNode) IsFile() bool {
+	return n.Children == nil
+}
+
+// MarshalBencode encodes a file as its attributes under the empty key and a
+// directory as the dictionary of its children
+func (n *FileTreeNode) MarshalBencode() ([]byte, error) {
+	if n.IsFile() {
+		return encoder.Marshal(map[string]fileAttributes{"": {Length: n.Length, PiecesRoot: n.PiecesRoot}})
+	}
+	return encoder.Marshal(n.Children)
+}
+
+// UnmarshalBencodeValue builds the tree from its parsed dictionary and
+// validates it in the same walk. A *ValidationError has a key relative to
+// the tree, and an empty key for the tree itself.
+func (t *FileTree) UnmarshalBencodeValue(v any) error {
+	tree, err := decodeDirectory(v, "")
+	if err != nil {
+		return err
+	}
+	*t = tree
+	return nil
+}
+
+// UnmarshalBencodeValue decodes a dictionary with an empty key as a file and
+// any other dictionary as a directory, validating it like a FileTree
+func (n *FileTreeNode) UnmarshalBencodeValue(v any) error {
+	node, err := decodeNode(v, "")
+	if err != nil {
+		return err
+	}
+	*n = *node
+	return nil
+}
+
+// decodeDirectory builds and validates the directory v whose key path is path
+func decodeDirectory(v any, path string) (FileTree, error) {
+	entries, ok := v.(map[string]any)
+	if !ok {
+		return nil, &parser.UnmarshalTypeError{Value: kindOf(v), Type: reflect.TypeFor[FileTree](), Path: path}
+	}
+	if len(entries) == 0 {
+		return nil, invalid(path, "empty directory")
+	}
+
+	tree := make(FileTree, len(entries))
+	for _, name := range slices.Sorted(maps.Keys(entries)) {
+		childPath := joinKey(path, name)
+		if !isPathComponent(name) {
+			return nil, invalid(childPath, "invalid path component %q", name)
+		}
+		node, err := decodeNode(entries[name], childPath)
+		if err != nil {
+			return nil, err
+		}
+		tree[name] = node
+	}
+	return tree, nil
+}
+
+// decodeNode builds and validates the file or directory v whose key path is path
+func decodeNode(v any, path string) (*FileTreeNode, error) {
+	entries, ok := v.(map[string]any)
+	if !ok {
+		return nil, &parser.UnmarshalTypeError{Value: kindOf(v), Type: reflect.TypeFor[FileTreeNode](), Path: path}
+	}
+	raw, isFile := entries[""]
+	if !isFile {
+		children, err := decodeDirectory(entries, path)
+		if err != nil {
+			return nil, err
+		}
+		return &FileTreeNode{Children: children}, nil
+	}
+	if len(entries) != 1 {
+		return nil, invalid(path, "file has entries besides its attributes")
+	}
+
+	attributes, ok := raw.(map[string]any)
+	if !ok {
+		return nil, &parser.UnmarshalTypeError{Value: kindOf(raw), Type: reflect.TypeFor[fileAttributes](), Path: joinKey(path, "")}
+	}
+	rawLength, ok := attributes["length"]
+	if !ok {
+		return nil, missing(joinKey(path, "length"))
+	}
+	length, ok := rawLength.(int64)
+	if !ok {
+		return nil, &parser.UnmarshalTypeError{Value: kindOf(rawLength), Type: reflect.TypeFor[int64](), Path: joinKey(path, "length")}
+	}
+	if length < 0 {
+		return nil, invalid(joinKey(path, "length"), "negative length %d", length)
+	}
+
+	node := &FileTreeNode{Length: length}
+	rawRoot, hasRoot := attributes["pieces root"]
+	if !hasRoot {
+		if length > 0 {
+			return nil, missing(joinKey(path, "pieces root"))
+		}
+		return node, nil
+	}
+	root, ok := rawRoot.(string)
+	if !ok {
+		return nil, &parser.UnmarshalTypeError{Value: kindOf(rawRoot), Type: reflect.TypeFor[[]byte](), Path: joinKey(path, "pieces root")}
+	}
+	if len(root) != sha256.Size {
+		return nil, invalid(joinKey(path, "pieces root"), "length %d is not %d", len(root), sha256.Size)
+	}
+	node.PiecesRoot = []byte(root)
+	return node, nil
+}
+
+// kindOf names the bencode kind of a parsed value
+func kindOf(v any) string {
+	switch v := v.(type) {
+	case int64:
+		return "integer"
+	case *big.Int:
+		return "integer " + v.String()
+	case string:
+		return "string"
+	case []any:
+		return "list"
+	}
+	return "dictionary"
+}
+
+// joinKey appends a key to a key path
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Files returns the files below t, depth first in key order
+func (t FileTree) Files() []TreeFile {
+	var files []TreeFile
+	t.walk(nil, func(path []string, n *FileTreeNode) {
+		files = append(files, TreeFile{Path: path, Length: n.Length, PiecesRoot: n.PiecesRoot})
+	})
+	return files
+}
+
+// walk calls fn for every file below t with the file's full path. The
+// directories share dir's backing array, so only file paths are copied.
+func (t FileTree) walk(dir []string, fn func(path []string, n *FileTreeNode)) {
+	for _, name := range slices.Sorted(maps.Keys(t)) {
+		path := append(dir, name)
+		if n := t[name]; n.IsFile() {
+			fn(slices.Clone(path), n)
+		} else {
+			n.Children.walk(path, fn)
+		}
+	}
+}
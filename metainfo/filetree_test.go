@@ -0,0 +1,107 @@
+package metainfo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kcabhinav/benparse/encoder"
+	"github.com/kcabhinav/benparse/parser"
+)
+
+func TestFileTree(t *testing.T) {
+	root := string(make([]byte, 32))
+	data := "d3:dird1:bd0:d6:lengthi2e11:pieces root32:" + root + "ee1:ad0:d6:lengthi0eeee" +
+		"1:zd0:d6:lengthi1e11:pieces root32:" + root + "eee"
+
+	t.Run("Testing decoding", func(t *testing.T) {
+		var tree FileTree
+		if err := parser.Unmarshal([]byte(data), &tree); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		dir := tree["dir"]
+		if dir.IsFile() || len(dir.Children) != 2 || !tree["z"].IsFile() || tree["z"].Length != 1 {
+			t.Errorf("Got %+v", tree)
+		}
+		expected := []TreeFile{
+			{Path: []string{"dir", "a"}},
+			{Path: []string{"dir", "b"}, Length: 2, PiecesRoot: []byte(root)},
+			{Path: []string{"z"}, Length: 1, PiecesRoot: []byte(root)},
+		}
+		if files := tree.Files(); !reflect.DeepEqual(files, expected) {
+			t.Errorf("Got %+v Wanted %+v", files, expected)
+		}
+	})
+
+	t.Run("Testing encoding", func(t *testing.T) {
+		var tree FileTree
+		if err := parser.Unmarshal([]byte(data), &tree); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		encoded, err := encoder.Marshal(tree)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		// The keys of the input are unsorted, so compare with its canonical form
+		canonical := "d3:dird1:ad0:d6:lengthi0eee1:bd0:d6:lengthi2e11:pieces root32:" + root + "eee" +
+			"1:zd0:d6:lengthi1e11:pieces root32:" + root + "eee"
+		if string(encoded) != canonical {
+			t.Errorf("Got %q Wanted %q", encoded, canonical)
+		}
+	})
+
+	t.Run("Testing invalid nodes", func(t *testing.T) {
+		for _, input := range []string{"d1:ai1ee", "d1:ad0:i1eee", "d1:ad0:d6:length1:xeee"} {
+			var tree FileTree
+			var typeErr *parser.UnmarshalTypeError
+			if err := parser.Unmarshal([]byte(input), &tree); !errors.As(err, &typeErr) {
+				t.Errorf("%q: Got %v Wanted *parser.UnmarshalTypeError", input, err)
+			}
+		}
+	})
+	t.Run("Testing validation", func(t *testing.T) {
+		tests := []struct {
+			input string
+			key   string
+		}{
+			{"de", ""},
+			{"d1:ad0:d6:lengthi0ee1:bdeee", "a"},
+			{"d1:ad1:bd0:d6:lengthi1eeeee", "a.b.pieces root"},
+			{"d2:..d0:d6:lengthi0eeee", ".."},
+		}
+
+		for _, test := range tests {
+			var tree FileTree
+			var validationErr *ValidationError
+			if err := parser.Unmarshal([]byte(test.input), &tree); !errors.As(err, &validationErr) || validationErr.Key != test.key {
+				t.Errorf("%q: Got %v Wanted *ValidationError at %q", test.input, err, test.key)
+			}
+		}
+	})
+
+	t.Run("Testing deep trees decode in one pass", func(t *testing.T) {
+		const depth = 5000
+		deep := strings.Repeat("d1:a", depth) + "d0:d6:lengthi0eee" + strings.Repeat("e", depth)
+
+		start := time.Now()
+		var tree FileTree
+		if err := parser.Unmarshal([]byte(deep), &tree); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Got %v Wanted well under a second", elapsed)
+		}
+		if files := tree.Files(); len(files) != 1 || len(files[0].Path) != depth {
+			t.Errorf("Got %d files Wanted one at depth %d", len(files), depth)
+		}
+
+		// The tree counts towards the caller's nesting limit
+		err := parser.UnmarshalWithOptions([]byte(deep), &tree, parser.Options{MaxDepth: depth})
+		if !errors.Is(err, parser.ErrMaxDepth) {
+			t.Errorf("Got %v Wanted %v", err, parser.ErrMaxDepth)
+		}
+	})
+}
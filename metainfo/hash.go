@@ -0,0 +1,96 @@
+package metainfo
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/kcabhinav/benparse/encoder"
+)
+
+// HashV1 is a BitTorrent v1 info-hash: the SHA-1 of the bencoded info
+// dictionary
+type HashV1 [sha1.Size]byte
+
+// String returns the hash as 40 lowercase hex digits
+func (h HashV1) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// Base32 returns the hash as 32 base32 characters, the form some magnet
+// links use
+func (h HashV1) Base32() string {
+	return base32.StdEncoding.EncodeToString(h[:])
+}
+
+// ParseHashV1 parses a hash in either the hex or the base32 form, ignoring
+// case
+func ParseHashV1(s string) (HashV1, error) {
+	var h HashV1
+	var err error
+	switch len(s) {
+	case hex.EncodedLen(len(h)):
+		_, err = hex.Decode(h[:], []byte(s))
+	case base32.StdEncoding.EncodedLen(len(h)):
+		_, err = base32.StdEncoding.Decode(h[:], []byte(strings.ToUpper(s)))
+	default:
+		return h, fmt.Errorf("invalid info-hash length %d", len(s))
+	}
+	if err != nil {
+		return HashV1{}, fmt.Errorf("invalid info-hash %q: %w", s, err)
+	}
+	return h, nil
+}
+
+// HashV2 is a BitTorrent v2 info-hash: the SHA-256 of the bencoded info
+// dictionary
+type HashV2 [sha256.Size]byte
+
+// String returns the hash as 64 lowercase hex digits
+func (h HashV2) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// Truncate returns the first 20 bytes of the hash, which stand in for the
+// info-hash of a v2 torrent in the tracker, DHT and peer protocols
+func (h HashV2) Truncate() HashV1 {
+	return HashV1(h[:sha1.Size])
+}
+
+// ParseHashV2 parses a hash in the hex form, ignoring case
+func ParseHashV2(s string) (HashV2, error) {
+	var h HashV2
+	if len(s) != hex.EncodedLen(len(h)) {
+		return h, fmt.Errorf("invalid info-hash length %d", len(s))
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return HashV2{}, fmt.Errorf("invalid info-hash %q: %w", s, err)
+	}
+	return h, nil
+}
+
+// InfoHashV1 returns the v1 info-hash of the torrent. It hashes InfoBytes,
+// the original bytes of the info dictionary, so that the result matches
+// what clients compute even for non-canonical files. A Metainfo without
+// InfoBytes hashes the canonical encoding of Info instead.
+func (m *Metainfo) InfoHashV1() HashV1 {
+	return sha1.Sum(m.infoBytes())
+}
+
+// InfoHashV2 returns the v2 info-hash of the torrent, hashing the same bytes
+// as InfoHashV1. It is only meaningful for v2 and hybrid torrents.
+func (m *Metainfo) InfoHashV2() HashV2 {
+	return sha256.Sum256(m.infoBytes())
+}
+
+func (m *Metainfo) infoBytes() []byte {
+	if len(m.InfoBytes) > 0 {
+		return []byte(m.InfoBytes)
+	}
+	// Info only has field types the encoder supports, so this can't fail
+	info, _ := encoder.Marshal(m.Info)
+	return info
+}
@@ -0,0 +1,124 @@
+package metainfo
+
+import (
+	"crypto/sha1"
+	"strings"
+	"testing"
+)
+
+func TestInfoHashV1(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"testdata/single.torrent", "bbd70780a507a55568ee211585bf70591bf7a1b3"},
+		{"testdata/multi.torrent", "f0568aa564340c106cf6a6e0d7b0775a0a96999a"},
+	}
+
+	for _, test := range tests {
+		m, err := Load(test.path)
+		if err != nil {
+			t.Fatalf("Load(%s): unexpected error: %v", test.path, err)
+		}
+		if got := m.InfoHashV1().String(); got != test.expected {
+			t.Errorf("InfoHashV1(%s): Got %v Wanted %v", test.path, got, test.expected)
+		}
+	}
+
+	t.Run("Testing original bytes are hashed", func(t *testing.T) {
+		// Keys out of order: a re-encoding would sort them and change the hash
+		info := "d6:lengthi5e4:name1:a6:pieces20:xxxxxxxxxxxxxxxxxxxx12:piece lengthi8ee"
+		m, err := Read(strings.NewReader("d4:info" + info + "e"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, want := m.InfoHashV1(), HashV1(sha1.Sum([]byte(info))); got != want {
+			t.Errorf("Got %v Wanted %v", got, want)
+		}
+	})
+
+	t.Run("Testing in-memory metainfo", func(t *testing.T) {
+		m := Metainfo{Info: Info{Name: "a", PieceLength: 8, Pieces: []byte(strings.Repeat("x", 20)), Length: 5}}
+		info := "d6:lengthi5e4:name1:a12:piece lengthi8e6:pieces20:xxxxxxxxxxxxxxxxxxxxe"
+		if got, want := m.InfoHashV1(), HashV1(sha1.Sum([]byte(info))); got != want {
+			t.Errorf("Got %v Wanted %v", got, want)
+		}
+	})
+}
+
+func TestParseHashV1(t *testing.T) {
+	const hexHash = "bbd70780a507a55568ee211585bf70591bf7a1b3"
+	const base32Hash = "XPLQPAFFA6SVK2HOEEKYLP3QLEN7PINT"
+
+	for _, input := range []string{hexHash, strings.ToUpper(hexHash), base32Hash, strings.ToLower(base32Hash)} {
+		h, err := ParseHashV1(input)
+		if err != nil {
+			t.Errorf("ParseHashV1(%s): unexpected error: %v", input, err)
+			continue
+		}
+		if h.String() != hexHash || h.Base32() != base32Hash {
+			t.Errorf("ParseHashV1(%s): Got %v / %v", input, h, h.Base32())
+		}
+	}
+
+	for _, input := range []string{"", "abc", strings.Repeat("z", 40), strings.Repeat("1", 32)} {
+		if _, err := ParseHashV1(input); err == nil {
+			t.Errorf("ParseHashV1(%q): Error expected. Got nil", input)
+		}
+	}
+}
+
+func TestInfoHashV2(t *testing.T) {
+	tests := []struct {
+		path string
+		v1   string
+		v2   string
+	}{
+		{"testdata/v2.torrent", "", "7719eb2ff855e5e1eb7c69ef0bd41dfed9fefe20ab87faaf50d226461985fbf6"},
+		{"testdata/hybrid.torrent", "c81c56626ba2bd46fe69f0e6d553c323f4faa893", "0c120d530ef634daf1705b8c5174fb382a5e0ae725981b1c47c7abfd122cf244"},
+	}
+
+	for _, test := range tests {
+		m, err := Load(test.path)
+		if err != nil {
+			t.Fatalf("Load(%s): unexpected error: %v", test.path, err)
+		}
+		if got := m.InfoHashV2().String(); got != test.v2 {
+			t.Errorf("InfoHashV2(%s): Got %v Wanted %v", test.path, got, test.v2)
+		}
+		if got := m.InfoHashV2().Truncate().String(); got != test.v2[:40] {
+			t.Errorf("Truncate(%s): Got %v Wanted %v", test.path, got, test.v2[:40])
+		}
+		if test.v1 != "" && m.InfoHashV1().String() != test.v1 {
+			t.Errorf("InfoHashV1(%s): Got %v Wanted %v", test.path, m.InfoHashV1(), test.v1)
+		}
+	}
+
+	t.Run("Testing in-memory metainfo", func(t *testing.T) {
+		m, err := Load("testdata/v2.torrent")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := m.InfoHashV2()
+		m.InfoBytes = ""
+		if got := m.InfoHashV2(); got != want {
+			t.Errorf("Got %v Wanted %v", got, want)
+		}
+	})
+}
+
+func TestParseHashV2(t *testing.T) {
+	const hexHash = "7719eb2ff855e5e1eb7c69ef0bd41dfed9fefe20ab87faaf50d226461985fbf6"
+
+	for _, input := range []string{hexHash, strings.ToUpper(hexHash)} {
+		if h, err := ParseHashV2(input); err != nil || h.String() != hexHash {
+			t.Errorf("ParseHashV2(%s): Got %v, %v", input, h, err)
+		}
+	}
+
+	for _, input := range []string{"", hexHash[:40], strings.Repeat("z", 64)} {
+		if _, err := ParseHashV2(input); err == nil {
+			t.Errorf("ParseHashV2(%q): Error expected. Got nil", input)
+		}
+	}
+}
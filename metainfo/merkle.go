@@ -0,0 +1,77 @@
+package metainfo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"path"
+	"slices"
+)
+
+// BlockSize is the size of the blocks whose SHA-256 hashes are the leaves of
+// a v2 file's merkle tree
+const BlockSize = 16 << 10
+
+// VerifyPieceLayers checks that every file of the file tree larger than a
+// piece has a piece layer that hashes up to the file's pieces root. Smaller
+// files have no layer, and their root can only be checked against the data.
+func (m *Metainfo) VerifyPieceLayers() error {
+	pieceLength := m.Info.PieceLength
+	pad := padHash(pieceLength)
+	for _, f := range m.Info.FileTree.Files() {
+		if f.Length <= pieceLength {
+			continue
+		}
+
+		name := path.Join(f.Path...)
+		layer, ok := m.PieceLayers[string(f.PiecesRoot)]
+		if !ok {
+			return &ValidationError{Key: "piece layers", Err: fmt.Errorf("%w: layer of %q", ErrMissingKey, name)}
+		}
+		want := int((f.Length + pieceLength - 1) / pieceLength)
+		if len(layer) != want*sha256.Size {
+			return invalid("piece layers", "layer of %q has %d bytes, want %d", name, len(layer), want*sha256.Size)
+		}
+
+		hashes := make([][sha256.Size]byte, want)
+		for i := range hashes {
+			copy(hashes[i][:], layer[i*sha256.Size:])
+		}
+		if root := merkleRoot(hashes, pad); !bytes.Equal(root[:], f.PiecesRoot) {
+			return invalid("piece layers", "layer of %q does not match its pieces root", name)
+		}
+	}
+	return nil
+}
+
+// merkleRoot returns the root of the tree over leaves, padded on the right
+// to a power of two with pad
+func merkleRoot(leaves [][sha256.Size]byte, pad [sha256.Size]byte) [sha256.Size]byte {
+	layer := slices.Clip(leaves)
+	for len(layer) > 1 {
+		if len(layer)%2 != 0 {
+			layer = append(layer, pad)
+		}
+		next := make([][sha256.Size]byte, len(layer)/2)
+		for i := range next {
+			next[i] = hashPair(layer[2*i], layer[2*i+1])
+		}
+		layer = next
+		pad = hashPair(pad, pad)
+	}
+	return layer[0]
+}
+
+// padHash returns the root of a piece of zero leaves, which pads a piece
+// layer. Leaves past the end of a file are zero rather than hashes of zeros.
+func padHash(pieceLength int64) [sha256.Size]byte {
+	var h [sha256.Size]byte
+	for n := int64(BlockSize); n < pieceLength; n *= 2 {
+		h = hashPair(h, h)
+	}
+	return h
+}
+
+func hashPair(left, right [sha256.Size]byte) [sha256.Size]byte {
+	return sha256.Sum256(append(left[:], right[:]...))
+}
@@ -0,0 +1,37 @@
+package metainfo
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	var zero [sha256.Size]byte
+	a, b, c := sha256.Sum256([]byte("a")), sha256.Sum256([]byte("b")), sha256.Sum256([]byte("c"))
+
+	t.Run("Testing pad hashes", func(t *testing.T) {
+		if got := padHash(BlockSize); got != zero {
+			t.Errorf("Got %x Wanted %x", got, zero)
+		}
+		if got, want := padHash(4*BlockSize), hashPair(hashPair(zero, zero), hashPair(zero, zero)); got != want {
+			t.Errorf("Got %x Wanted %x", got, want)
+		}
+	})
+
+	t.Run("Testing roots", func(t *testing.T) {
+		pad := padHash(2 * BlockSize)
+		tests := []struct {
+			leaves   [][sha256.Size]byte
+			expected [sha256.Size]byte
+		}{
+			{[][sha256.Size]byte{a}, a},
+			{[][sha256.Size]byte{a, b}, hashPair(a, b)},
+			{[][sha256.Size]byte{a, b, c}, hashPair(hashPair(a, b), hashPair(c, pad))},
+		}
+		for _, test := range tests {
+			if got := merkleRoot(test.leaves, pad); got != test.expected {
+				t.Errorf("%d leaves: Got %x Wanted %x", len(test.leaves), got, test.expected)
+			}
+		}
+	})
+}
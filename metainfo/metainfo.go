@@ -0,0 +1,185 @@
+package metainfo
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kcabhinav/benparse/parser"
+)
+
+// Metainfo is the decoded contents of a .torrent file
+type Metainfo struct {
+	Announce     string     `bencode:"announce,omitempty"`
+	AnnounceList [][]string `bencode:"announce-list,omitempty"`
+	Comment      string     `bencode:"comment,omitempty"`
+	CreatedBy    string     `bencode:"created by,omitempty"`
+	CreationDate int64      `bencode:"creation date,omitempty"` // seconds since the Unix epoch
+	Encoding     string     `bencode:"encoding,omitempty"`
+	Info         Info       `bencode:"info"`
+
+	// PieceLayers maps the pieces root of every v2 file larger than a piece
+	// to the concatenated SHA-256 hashes of its pieces
+	PieceLayers map[string][]byte `bencode:"piece layers,omitempty"`
+
+	// InfoBytes holds the info dictionary exactly as it appeared in the
+	// loaded file, which is what its info-hash covers. It is empty for a
+	// Metainfo built in memory and is not updated when Info changes.
+	InfoBytes parser.RawValue `bencode:"-"`
+}
+
+// Info is the info dictionary of a torrent, which describes its content
+type Info struct {
+	Name        string      `bencode:"name"`
+	PieceLength int64       `bencode:"piece length"`
+	Pieces      []byte      `bencode:"pieces,omitempty"` // concatenated SHA-1 hashes of every piece, absent in v2-only torrents
+	Length      int64       `bencode:"length,omitempty"`
+	Files       []FileEntry `bencode:"files,omitempty"`
+	Private     bool        `bencode:"private,omitempty"`
+
+	// MetaVersion is 2 for v2 and hybrid torrents, which describe their
+	// content with FileTree, and 0 for v1 torrents
+	MetaVersion int64    `bencode:"meta version,omitempty"`
+	FileTree    FileTree `bencode:"file tree,omitempty"`
+}
+
+// FileEntry is a single file of a multi-file torrent
+type FileEntry struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`           // path components below the torrent's directory
+	Attr   string   `bencode:"attr,omitempty"` // BEP 47 attributes, such as "p" for padding
+}
+
+// Load reads and validates the torrent file at path
+func Load(path string) (*Metainfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// Read reads and validates a torrent from r
+func Read(r io.Reader) (*Metainfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+func decode(data []byte) (*Metainfo, error) {
+	var m Metainfo
+	if err := parser.Unmarshal(data, &m); err != nil {
+		// The file tree reports problems relative to itself
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			if validationErr.Key == "" {
+				validationErr.Key = "info.file tree"
+			} else {
+				validationErr.Key = "info.file tree." + validationErr.Key
+			}
+		}
+		return nil, err
+	}
+	top, err := keysOf(parser.RawValue(data))
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(top, &m); err != nil {
+		return nil, err
+	}
+	m.InfoBytes = top["info"]
+	return &m, nil
+}
+
+// CreationTime returns the creation date, or the zero time if it is not set
+func (m *Metainfo) CreationTime() time.Time {
+	if m.CreationDate == 0 {
+		return time.Time{}
+	}
+	return time.Unix(m.CreationDate, 0)
+}
+
+// Trackers returns the announce URLs grouped in tiers, taken from the
+// announce-list when present and from announce otherwise
+func (m *Metainfo) Trackers() [][]string {
+	if len(m.AnnounceList) > 0 {
+		return m.AnnounceList
+	}
+	if m.Announce != "" {
+		return [][]string{{m.Announce}}
+	}
+	return nil
+}
+
+// IsV1 reports whether the torrent has v1 piece hashes, which hybrid
+// torrents carry alongside the file tree
+func (info *Info) IsV1() bool {
+	return !info.IsV2() || info.Pieces != nil
+}
+
+// IsV2 reports whether the torrent is a v2 or hybrid torrent
+func (info *Info) IsV2() bool {
+	return info.MetaVersion == 2
+}
+
+// IsMultiFile reports whether the torrent holds a directory of files rather
+// than a single file. A v2 torrent holds a single file when its file tree is
+// just that file under the torrent's name.
+func (info *Info) IsMultiFile() bool {
+	if info.IsV1() {
+		return info.Files != nil
+	}
+	n, ok := info.FileTree[info.Name]
+	return len(info.FileTree) != 1 || !ok || !n.IsFile()
+}
+
+// TotalLength returns the combined length of all files
+func (info *Info) TotalLength() int64 {
+	var total int64
+	for _, f := range info.FileEntries() {
+		total += f.Length
+	}
+	return total
+}
+
+// NumPieces returns the number of v1 pieces
+func (info *Info) NumPieces() int {
+	return len(info.Pieces) / PieceHashSize
+}
+
+// PieceHash returns the SHA-1 hash of piece i
+func (info *Info) PieceHash(i int) []byte {
+	return info.Pieces[i*PieceHashSize : (i+1)*PieceHashSize]
+}
+
+// IsPadding reports whether the file is a BEP 47 padding file, which holds
+// zeros to align the next file to a piece and isn't stored on disk
+func (f *FileEntry) IsPadding() bool {
+	return strings.Contains(f.Attr, "p")
+}
+
+// FileEntries returns the files of the torrent. A single-file torrent yields
+// one entry with an empty Path, since its file is named by Info.Name. The
+// files of a v2-only torrent are taken from its file tree.
+func (info *Info) FileEntries() []FileEntry {
+	if info.IsV1() {
+		if info.IsMultiFile() {
+			return info.Files
+		}
+		return []FileEntry{{Length: info.Length}}
+	}
+
+	files := info.FileTree.Files()
+	if !info.IsMultiFile() {
+		return []FileEntry{{Length: files[0].Length}}
+	}
+	entries := make([]FileEntry, len(files))
+	for i, f := range files {
+		entries[i] = FileEntry{Length: f.Length, Path: f.Path}
+	}
+	return entries
+}
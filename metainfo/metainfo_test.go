@@ -0,0 +1,316 @@
+package metainfo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kcabhinav/benparse/encoder"
+	"github.com/kcabhinav/benparse/parser"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("Testing single-file torrent", func(t *testing.T) {
+		m, err := Load("testdata/single.torrent")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if m.Announce != "http://tracker.example.com/announce" || m.Comment != "single file test torrent" || m.CreatedBy != "benparse" {
+			t.Errorf("Got %+v", m)
+		}
+		if got := m.CreationTime(); !got.Equal(time.Unix(1700000000, 0)) {
+			t.Errorf("Got %v Wanted %v", got, time.Unix(1700000000, 0))
+		}
+		if m.Info.IsMultiFile() || m.Info.Name != "single.bin" || m.Info.TotalLength() != 100000 {
+			t.Errorf("Got %+v", m.Info)
+		}
+		if m.Info.PieceLength != 32768 || m.Info.NumPieces() != 4 || len(m.Info.PieceHash(3)) != PieceHashSize {
+			t.Errorf("Got piece length %d and %d pieces", m.Info.PieceLength, m.Info.NumPieces())
+		}
+		if entries := m.Info.FileEntries(); len(entries) != 1 || entries[0].Length != 100000 || len(entries[0].Path) != 0 {
+			t.Errorf("Got %+v", entries)
+		}
+		if trackers := m.Trackers(); !reflect.DeepEqual(trackers, [][]string{{m.Announce}}) {
+			t.Errorf("Got %v", trackers)
+		}
+	})
+
+	t.Run("Testing multi-file torrent", func(t *testing.T) {
+		m, err := Load("testdata/multi.torrent")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []FileEntry{
+			{Length: 50000, Path: []string{"a.txt"}},
+			{Length: 70000, Path: []string{"dir", "b.bin"}},
+			{Length: 0, Path: []string{"dir", "empty"}},
+		}
+		if !m.Info.IsMultiFile() || !reflect.DeepEqual(m.Info.FileEntries(), expected) {
+			t.Errorf("Got %+v Wanted %+v", m.Info.Files, expected)
+		}
+		if m.Info.TotalLength() != 120000 || m.Info.NumPieces() != 4 || !m.Info.Private {
+			t.Errorf("Got %+v", m.Info)
+		}
+		if len(m.Trackers()) != 2 || m.Comment != "" || !m.CreationTime().Equal(time.Unix(1700000001, 0)) {
+			t.Errorf("Got %+v", m)
+		}
+	})
+
+	t.Run("Testing v2 torrent", func(t *testing.T) {
+		m, err := Load("testdata/v2.torrent")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []FileEntry{
+			{Length: 50000, Path: []string{"dir", "a.txt"}},
+			{Length: 70000, Path: []string{"dir", "b.bin"}},
+			{Length: 0, Path: []string{"empty"}},
+			{Length: 1000, Path: []string{"small.txt"}},
+		}
+		if !m.Info.IsV2() || m.Info.IsV1() || !m.Info.IsMultiFile() || !reflect.DeepEqual(m.Info.FileEntries(), expected) {
+			t.Errorf("Got %+v Wanted %+v", m.Info.FileEntries(), expected)
+		}
+		if m.Info.TotalLength() != 121000 || m.Info.NumPieces() != 0 || len(m.PieceLayers) != 2 {
+			t.Errorf("Got %+v", m.Info)
+		}
+		if b := m.Info.FileTree["dir"].Children["b.bin"]; !b.IsFile() || b.Length != 70000 || len(m.PieceLayers[string(b.PiecesRoot)]) != 3*32 {
+			t.Errorf("Got %+v", b)
+		}
+	})
+
+	t.Run("Testing hybrid torrent", func(t *testing.T) {
+		m, err := Load("testdata/hybrid.torrent")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !m.Info.IsV1() || !m.Info.IsV2() || m.Info.IsMultiFile() || m.Info.TotalLength() != 100000 || m.Info.NumPieces() != 4 {
+			t.Errorf("Got %+v", m.Info)
+		}
+		files := m.Info.FileTree.Files()
+		if len(files) != 1 || files[0].Length != 100000 || !reflect.DeepEqual(files[0].Path, []string{"hybrid.bin"}) {
+			t.Errorf("Got %+v", files)
+		}
+	})
+
+	t.Run("Testing missing file", func(t *testing.T) {
+		if _, err := Load("testdata/missing.torrent"); err == nil {
+			t.Error("Error expected. Got nil")
+		}
+	})
+}
+
+// validTorrent returns the fields of a minimal valid single-file torrent
+func validTorrent() (map[string]any, map[string]any) {
+	info := map[string]any{
+		"name":         "a",
+		"piece length": 4,
+		"pieces":       strings.Repeat("x", 40),
+		"length":       5,
+	}
+	return map[string]any{"announce": "http://t", "info": info}, info
+}
+
+func TestValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(top, info map[string]any)
+		key     string
+		missing bool
+	}{
+		{"missing info", func(top, info map[string]any) { delete(top, "info") }, "info", true},
+		{"missing name", func(top, info map[string]any) { delete(info, "name") }, "info.name", true},
+		{"missing piece length", func(top, info map[string]any) { delete(info, "piece length") }, "info.piece length", true},
+		{"missing pieces", func(top, info map[string]any) { delete(info, "pieces") }, "info.pieces", true},
+		{"missing length and files", func(top, info map[string]any) { delete(info, "length") }, "info.length", true},
+		{"both length and files", func(top, info map[string]any) {
+			info["files"] = []any{map[string]any{"length": 5, "path": []any{"a"}}}
+		}, "info.files", false},
+		{"zero piece length", func(top, info map[string]any) { info["piece length"] = 0 }, "info.piece length", false},
+		{"huge piece length", func(top, info map[string]any) { info["piece length"] = 1 << 50 }, "info.piece length", false},
+		{"truncated pieces", func(top, info map[string]any) { info["pieces"] = strings.Repeat("x", 39) }, "info.pieces", false},
+		{"wrong piece count", func(top, info map[string]any) { info["length"] = 9 }, "info.pieces", false},
+		{"unsafe name", func(top, info map[string]any) { info["name"] = ".." }, "info.name", false},
+		{"missing file path", func(top, info map[string]any) {
+			delete(info, "length")
+			info["files"] = []any{map[string]any{"length": 5}}
+		}, "info.files[0].path", true},
+		{"unsafe file path", func(top, info map[string]any) {
+			delete(info, "length")
+			info["files"] = []any{map[string]any{"length": 5, "path": []any{"dir", "../x"}}}
+		}, "info.files[0].path", false},
+		{"empty file list", func(top, info map[string]any) {
+			delete(info, "length")
+			info["files"] = []any{}
+		}, "info.files", false},
+	}
+
+	for _, test := range tests {
+		top, info := validTorrent()
+		test.edit(top, info)
+		data, err := encoder.Encode(top)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		_, err = Read(bytes.NewReader(data))
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected *ValidationError, got %v", test.name, err)
+			continue
+		}
+		if validationErr.Key != test.key || errors.Is(err, ErrMissingKey) != test.missing {
+			t.Errorf("%s: Got %v Wanted key %q (missing %v)", test.name, err, test.key, test.missing)
+		}
+	}
+
+	t.Run("Testing valid minimal torrent", func(t *testing.T) {
+		top, _ := validTorrent()
+		data, _ := encoder.Encode(top)
+		if _, err := Read(bytes.NewReader(data)); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Testing malformed bencode", func(t *testing.T) {
+		_, err := Read(strings.NewReader("d4:infod4:name"))
+		var syntaxErr *parser.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Expected *parser.SyntaxError, got %v", err)
+		}
+	})
+}
+
+// validV2Torrent returns the fields of a minimal valid v2 torrent holding a
+// file of two pieces and an empty file
+func validV2Torrent() (map[string]any, map[string]any) {
+	leaf := sha256.Sum256([]byte("x"))
+	layer := append(leaf[:], leaf[:]...)
+	root := sha256.Sum256(layer)
+	info := map[string]any{
+		"name":         "a",
+		"piece length": BlockSize,
+		"meta version": 2,
+		"file tree": map[string]any{
+			"big":   map[string]any{"": map[string]any{"length": BlockSize + 1, "pieces root": root[:]}},
+			"empty": map[string]any{"": map[string]any{"length": 0}},
+		},
+	}
+	top := map[string]any{"info": info, "piece layers": map[string]any{string(root[:]): layer}}
+	return top, info
+}
+
+func TestValidationV2(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(top, info map[string]any)
+		key     string
+		missing bool
+	}{
+		{"unsupported version", func(top, info map[string]any) { info["meta version"] = 3 }, "info.meta version", false},
+		{"missing file tree", func(top, info map[string]any) { delete(info, "file tree") }, "info.file tree", true},
+		{"piece length below block size", func(top, info map[string]any) { info["piece length"] = BlockSize / 2 }, "info.piece length", false},
+		{"piece length not a power of two", func(top, info map[string]any) { info["piece length"] = 3 * BlockSize }, "info.piece length", false},
+		{"empty file tree", func(top, info map[string]any) { info["file tree"] = map[string]any{} }, "info.file tree", false},
+		{"empty directory", func(top, info map[string]any) {
+			info["file tree"] = map[string]any{"dir": map[string]any{}}
+		}, "info.file tree.dir", false},
+		{"unsafe path component", func(top, info map[string]any) {
+			info["file tree"] = map[string]any{"..": map[string]any{"": map[string]any{"length": 0}}}
+		}, "info.file tree...", false},
+		{"missing file length", func(top, info map[string]any) {
+			info["file tree"] = map[string]any{"f": map[string]any{"": map[string]any{}}}
+		}, "info.file tree.f.length", true},
+		{"missing pieces root", func(top, info map[string]any) {
+			info["file tree"] = map[string]any{"f": map[string]any{"": map[string]any{"length": 1}}}
+		}, "info.file tree.f.pieces root", true},
+		{"short pieces root", func(top, info map[string]any) {
+			info["file tree"] = map[string]any{"f": map[string]any{"": map[string]any{"length": 1, "pieces root": "x"}}}
+		}, "info.file tree.f.pieces root", false},
+		{"file with other entries", func(top, info map[string]any) {
+			info["file tree"] = map[string]any{"f": map[string]any{"": map[string]any{"length": 0}, "g": map[string]any{}}}
+		}, "info.file tree.f", false},
+		{"missing piece layer", func(top, info map[string]any) { top["piece layers"] = map[string]any{} }, "piece layers", true},
+		{"mismatched piece layer", func(top, info map[string]any) {
+			for root := range top["piece layers"].(map[string]any) {
+				top["piece layers"] = map[string]any{root: strings.Repeat("x", 64)}
+			}
+		}, "piece layers", false},
+		{"truncated piece layer", func(top, info map[string]any) {
+			for root, layer := range top["piece layers"].(map[string]any) {
+				top["piece layers"] = map[string]any{root: layer.([]byte)[:32]}
+			}
+		}, "piece layers", false},
+		{"hybrid without pieces", func(top, info map[string]any) { info["length"] = BlockSize + 1 }, "info.pieces", true},
+	}
+
+	for _, test := range tests {
+		top, info := validV2Torrent()
+		test.edit(top, info)
+		data, err := encoder.Encode(top)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		_, err = Read(bytes.NewReader(data))
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected *ValidationError, got %v", test.name, err)
+			continue
+		}
+		if validationErr.Key != test.key || errors.Is(err, ErrMissingKey) != test.missing {
+			t.Errorf("%s: Got %v Wanted key %q (missing %v)", test.name, err, test.key, test.missing)
+		}
+	}
+
+	t.Run("Testing valid minimal torrent", func(t *testing.T) {
+		top, _ := validV2Torrent()
+		data, _ := encoder.Encode(top)
+		if _, err := Read(bytes.NewReader(data)); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Testing torrent without piece layers", func(t *testing.T) {
+		top, _ := validV2Torrent()
+		delete(top, "piece layers")
+		data, _ := encoder.Encode(top)
+		m, err := Read(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := m.VerifyPieceLayers(); !errors.Is(err, ErrMissingKey) {
+			t.Errorf("Got %v Wanted %v", err, ErrMissingKey)
+		}
+	})
+	t.Run("Testing deep file trees load in linear time", func(t *testing.T) {
+		top, info := validV2Torrent()
+		var tree any = info["file tree"]
+		for range 4000 {
+			tree = map[string]any{"d": tree}
+		}
+		info["file tree"] = tree
+		data, _ := encoder.Encode(top)
+
+		start := time.Now()
+		m, err := Read(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Got %v Wanted well under a second", elapsed)
+		}
+		if files := m.Info.FileTree.Files(); len(files) != 2 || len(files[0].Path) != 4001 {
+			t.Errorf("Got %d files Wanted 2 at depth 4001", len(files))
+		}
+	})
+}
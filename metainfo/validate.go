@@ -0,0 +1,189 @@
+package metainfo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kcabhinav/benparse/parser"
+)
+
+// PieceHashSize is the length of each SHA-1 piece hash in Info.Pieces
+const PieceHashSize = 20
+
+// pieceLengthLimit is the largest piece length accepted, far beyond any in
+// use, so that a torrent can't make its readers allocate huge pieces
+const pieceLengthLimit = 1 << 30
+
+// ErrMissingKey is wrapped by the *ValidationError for a missing required key
+var ErrMissingKey = errors.New("missing required key")
+
+// ValidationError reports a torrent that is valid bencode but not a valid
+// metainfo file
+type ValidationError struct {
+	Key string // key path of the offending value, e.g. "info.piece length"
+	Err error  // ErrMissingKey or a description of the problem
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid metainfo key %q: %v", e.Key, e.Err)
+}
+
+// Unwrap returns the underlying cause of the error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func invalid(key, format string, args ...any) *ValidationError {
+	return &ValidationError{Key: key, Err: fmt.Errorf(format, args...)}
+}
+
+func missing(key string) *ValidationError {
+	return &ValidationError{Key: key, Err: ErrMissingKey}
+}
+
+// validate checks m against BEP 3 and, for v2 and hybrid torrents, BEP 52,
+// given the raw entries of the torrent it was decoded from. Presence is
+// checked on the raw input because a decoded zero value can't tell a missing
+// key from an empty one.
+func validate(top map[string]parser.RawValue, m *Metainfo) error {
+	if _, ok := top["info"]; !ok {
+		return missing("info")
+	}
+	info, err := keysOf(top["info"])
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{"name", "piece length"} {
+		if _, ok := info[key]; !ok {
+			return missing("info." + key)
+		}
+	}
+	if _, ok := info["meta version"]; ok && !m.Info.IsV2() {
+		return invalid("info.meta version", "unsupported version %d", m.Info.MetaVersion)
+	}
+	if _, ok := info["file tree"]; m.Info.IsV2() && !ok {
+		return missing("info.file tree")
+	}
+
+	// A v2 torrent is hybrid when it also has any of the v1 keys, and then
+	// needs all of them
+	_, hasPieces := info["pieces"]
+	_, hasLength := info["length"]
+	_, hasFiles := info["files"]
+	isV1 := !m.Info.IsV2() || hasPieces || hasLength || hasFiles
+	if isV1 {
+		if !hasPieces {
+			return missing("info.pieces")
+		}
+		switch {
+		case !hasLength && !hasFiles:
+			return &ValidationError{Key: "info.length", Err: fmt.Errorf("%w: one of length or files", ErrMissingKey)}
+		case hasLength && hasFiles:
+			return invalid("info.files", "both length and files are present")
+		}
+	}
+
+	if !isPathComponent(m.Info.Name) {
+		return invalid("info.name", "invalid name %q", m.Info.Name)
+	}
+	if m.Info.PieceLength <= 0 {
+		return invalid("info.piece length", "piece length %d is not positive", m.Info.PieceLength)
+	}
+	if m.Info.PieceLength > pieceLengthLimit {
+		return invalid("info.piece length", "piece length %d exceeds %d", m.Info.PieceLength, pieceLengthLimit)
+	}
+
+	if m.Info.IsV2() {
+		if err := validateV2(top, m); err != nil {
+			return err
+		}
+	}
+	if !isV1 {
+		return nil
+	}
+
+	if len(m.Info.Pieces)%PieceHashSize != 0 {
+		return invalid("info.pieces", "length %d is not a multiple of %d", len(m.Info.Pieces), PieceHashSize)
+	}
+
+	if hasFiles {
+		if err := validateFiles(info["files"], m.Info.Files); err != nil {
+			return err
+		}
+	} else if m.Info.Length < 0 {
+		return invalid("info.length", "negative length %d", m.Info.Length)
+	}
+
+	total := m.Info.TotalLength()
+	if want := (total + m.Info.PieceLength - 1) / m.Info.PieceLength; int64(m.Info.NumPieces()) != want {
+		return invalid("info.pieces", "%d pieces for %d bytes, want %d", m.Info.NumPieces(), total, want)
+	}
+	return nil
+}
+
+// validateV2 checks the keys BEP 52 adds, verifying the piece layers when
+// the torrent has them. The file tree validates itself as it is decoded.
+func validateV2(top map[string]parser.RawValue, m *Metainfo) error {
+	if pl := m.Info.PieceLength; pl < BlockSize || pl&(pl-1) != 0 {
+		return invalid("info.piece length", "piece length %d is not a power of two of at least %d", pl, BlockSize)
+	}
+	if _, ok := top["piece layers"]; !ok {
+		return nil
+	}
+	return m.VerifyPieceLayers()
+}
+
+func validateFiles(raw parser.RawValue, files []FileEntry) error {
+	if len(files) == 0 {
+		return invalid("info.files", "empty file list")
+	}
+
+	var err error
+	i := 0
+	for entry := range raw.Elements(&err) {
+		key := "info.files[" + strconv.Itoa(i) + "]"
+		keys, keyErr := keysOf(entry)
+		if keyErr != nil {
+			return keyErr
+		}
+		for _, name := range []string{"length", "path"} {
+			if _, ok := keys[name]; !ok {
+				return missing(key + "." + name)
+			}
+		}
+
+		f := files[i]
+		if f.Length < 0 {
+			return invalid(key+".length", "negative length %d", f.Length)
+		}
+		if len(f.Path) == 0 {
+			return invalid(key+".path", "empty path")
+		}
+		for _, component := range f.Path {
+			if !isPathComponent(component) {
+				return invalid(key+".path", "invalid path component %q", component)
+			}
+		}
+		i++
+	}
+	return err
+}
+
+// keysOf returns the entries of a dictionary by key without decoding them
+func keysOf(raw parser.RawValue) (map[string]parser.RawValue, error) {
+	var err error
+	keys := make(map[string]parser.RawValue)
+	for key, value := range raw.Entries(&err) {
+		keys[key] = value
+	}
+	return keys, err
+}
+
+// isPathComponent reports whether s names a single file or directory, so
+// that joining it to a directory can't escape that directory
+func isPathComponent(s string) bool {
+	return s != "" && s != "." && s != ".." && !strings.ContainsAny(s, "/\\\x00")
+}
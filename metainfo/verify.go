@@ -0,0 +1,287 @@
+package metainfo
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Status is the outcome of verifying a piece or a file
+type Status int
+
+const (
+	StatusOK      Status = iota
+	StatusMissing        // a file is not on disk
+	StatusShort          // a file ends before its length in the torrent
+	StatusCorrupt        // a piece fails its hash
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "ok"
+	case StatusMissing:
+		return "missing"
+	case StatusShort:
+		return "short"
+	case StatusCorrupt:
+		return "corrupt"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// VerifyOptions configures Verify
+type VerifyOptions struct {
+	Workers int // goroutines hashing pieces, defaults to GOMAXPROCS
+
+	// Progress, when set, is called after each piece with the number of
+	// pieces checked so far. Calls come from the goroutine running Verify.
+	Progress func(done, total int)
+}
+
+// VerifyReport is the result of Verify
+type VerifyReport struct {
+	Pieces []Status     // by piece index
+	Files  []FileReport // in the order of Info.FileEntries
+}
+
+// FileReport is the result of verifying a single file
+type FileReport struct {
+	FileEntry
+	Path   string // on disk
+	Size   int64  // on disk, 0 when missing
+	Status Status
+}
+
+// OK reports whether every piece matched its hash
+func (r *VerifyReport) OK() bool {
+	for _, s := range r.Pieces {
+		if s != StatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+// BadPieces returns the indexes of the pieces that didn't verify
+func (r *VerifyReport) BadPieces() []int {
+	var bad []int
+	for i, s := range r.Pieces {
+		if s != StatusOK {
+			bad = append(bad, i)
+		}
+	}
+	return bad
+}
+
+// Verify checks the content of the torrent stored below rootDir, where a
+// single file is named by Info.Name and the files of a multi-file torrent
+// are in a directory of that name. Pieces span file boundaries and are
+// hashed concurrently against their v1 hashes; padding files count as zeros.
+//
+// A piece is missing or short when a file it covers is, and corrupt when
+// its hash doesn't match. A file is missing or short by its own size, and
+// corrupt when a piece it overlaps is: a corrupt piece spanning files marks
+// each of them. Verify only returns an error for an I/O failure other than
+// a missing or short file, or for a torrent without v1 piece hashes.
+func Verify(info *Info, rootDir string, opts VerifyOptions) (*VerifyReport, error) {
+	if !info.IsV1() {
+		return nil, errors.New("torrent has no v1 piece hashes")
+	}
+	if info.PieceLength <= 0 {
+		return nil, fmt.Errorf("piece length %d is not positive", info.PieceLength)
+	}
+
+	entries := info.FileEntries()
+	report := &VerifyReport{
+		Pieces: make([]Status, info.NumPieces()),
+		Files:  make([]FileReport, len(entries)),
+	}
+	// offsets[i] is where file i starts within the content
+	offsets := make([]int64, len(entries)+1)
+	for i, entry := range entries {
+		f := &report.Files[i]
+		f.FileEntry = entry
+		f.Path = filepath.Join(append([]string{rootDir, info.Name}, entry.Path...)...)
+		offsets[i+1] = offsets[i] + entry.Length
+
+		if entry.IsPadding() {
+			f.Size = entry.Length
+			continue
+		}
+		stat, err := os.Stat(f.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			f.Status = StatusMissing
+		case err != nil:
+			return nil, err
+		case !stat.Mode().IsRegular():
+			return nil, fmt.Errorf("%s: not a regular file", f.Path)
+		default:
+			f.Size = stat.Size()
+			if f.Size < entry.Length {
+				f.Status = StatusShort
+			}
+		}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	v := &verifier{info: info, files: report.Files, offsets: offsets}
+
+	type result struct {
+		index  int
+		status Status
+		err    error
+	}
+	queue := make(chan int)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			// Pieces are hashed as they are read, so a piece larger than
+			// the buffer costs no more memory
+			buf := make([]byte, min(info.PieceLength, offsets[len(entries)], verifyBufferSize))
+			for i := range queue {
+				status, err := v.checkPiece(i, buf)
+				results <- result{index: i, status: status, err: err}
+			}
+		}()
+	}
+	go func() {
+		for i := range report.Pieces {
+			queue <- i
+		}
+		close(queue)
+		wg.Wait()
+		close(results)
+	}()
+
+	var err error
+	done := 0
+	for r := range results {
+		if r.err != nil && err == nil {
+			err = r.err
+		}
+		report.Pieces[r.index] = r.status
+		done++
+		if opts.Progress != nil {
+			opts.Progress(done, len(report.Pieces))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range report.Pieces {
+		if report.Pieces[i] != StatusCorrupt {
+			continue
+		}
+		first, last := v.filesOf(i)
+		for j := first; j <= last; j++ {
+			if f := &report.Files[j]; f.Status == StatusOK {
+				f.Status = StatusCorrupt
+			}
+		}
+	}
+	return report, nil
+}
+
+// verifyBufferSize bounds the buffer each Verify worker reads pieces through
+const verifyBufferSize = 1 << 20
+
+// verifier maps the pieces of a torrent onto its files
+type verifier struct {
+	info    *Info
+	files   []FileReport
+	offsets []int64
+}
+
+// filesOf returns the range of files that hold the bytes of piece i
+func (v *verifier) filesOf(i int) (first, last int) {
+	start, end := v.pieceBounds(i)
+	// The last file starting at or before an offset holds it, which skips
+	// any empty files there
+	first = sort.Search(len(v.files), func(j int) bool { return v.offsets[j+1] > start })
+	last = sort.Search(len(v.files), func(j int) bool { return v.offsets[j+1] >= end })
+	return first, last
+}
+
+// pieceBounds returns the content offsets of piece i
+func (v *verifier) pieceBounds(i int) (start, end int64) {
+	start = int64(i) * v.info.PieceLength
+	return start, min(start+v.info.PieceLength, v.offsets[len(v.files)])
+}
+
+// checkPiece reads piece i through buf and checks it against its hash
+func (v *verifier) checkPiece(i int, buf []byte) (Status, error) {
+	start, end := v.pieceBounds(i)
+	h := sha1.New()
+
+	first, last := v.filesOf(i)
+	for j := first; j <= last; j++ {
+		f := &v.files[j]
+		// The part of the piece within file j
+		from, to := max(start, v.offsets[j]), min(end, v.offsets[j+1])
+		if from >= to {
+			continue
+		}
+		offset := from - v.offsets[j]
+
+		switch {
+		case f.IsPadding():
+			clear(buf)
+			for n := to - from; n > 0; n -= int64(len(buf)) {
+				h.Write(buf[:min(n, int64(len(buf)))])
+			}
+			continue
+		case f.Status == StatusMissing:
+			return StatusMissing, nil
+		case offset+to-from > f.Size:
+			return StatusShort, nil
+		}
+		if status, err := hashChunk(h, f.Path, offset, to-from, buf); status != StatusOK || err != nil {
+			return status, err
+		}
+	}
+
+	if !bytes.Equal(h.Sum(nil), v.info.PieceHash(i)) {
+		return StatusCorrupt, nil
+	}
+	return StatusOK, nil
+}
+
+// hashChunk hashes n bytes of the file at path, starting at offset, reading
+// them through buf. A file that disappeared or shrank since it was measured
+// is missing or short.
+func hashChunk(h hash.Hash, path string, offset, n int64, buf []byte) (Status, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return StatusMissing, nil
+	} else if err != nil {
+		return StatusOK, err
+	}
+	defer file.Close()
+
+	copied, err := io.CopyBuffer(h, io.NewSectionReader(file, offset, n), buf)
+	if err != nil {
+		return StatusOK, err
+	}
+	if copied < n {
+		return StatusShort, nil
+	}
+	return StatusOK, nil
+}
@@ -0,0 +1,224 @@
+package metainfo
+
+import (
+	"crypto/sha1"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// verifyMulti writes the content of testdata/multi.torrent below a temporary
+// directory, lets edit change it and verifies it
+func verifyMulti(t *testing.T, edit func(dir string)) *VerifyReport {
+	t.Helper()
+	m, err := Load("testdata/multi.torrent")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	root := t.TempDir()
+	writeMultiContent(t, filepath.Join(root, "multi"))
+	edit(filepath.Join(root, "multi"))
+
+	report, err := Verify(&m.Info, root, VerifyOptions{Workers: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return report
+}
+
+// fileStatuses returns the status of every file in report
+func fileStatuses(report *VerifyReport) []Status {
+	statuses := make([]Status, len(report.Files))
+	for i, f := range report.Files {
+		statuses[i] = f.Status
+	}
+	return statuses
+}
+
+func TestVerify(t *testing.T) {
+	// Pieces of multi.torrent: 0 is in a.txt, 1 spans a.txt and dir/b.bin,
+	// 2 and 3 are in dir/b.bin
+	tests := []struct {
+		name   string
+		edit   func(dir string)
+		pieces []Status
+		files  []Status
+	}{
+		{
+			"intact content",
+			func(dir string) {},
+			[]Status{StatusOK, StatusOK, StatusOK, StatusOK},
+			[]Status{StatusOK, StatusOK, StatusOK},
+		},
+		{
+			"missing file",
+			func(dir string) { os.Remove(filepath.Join(dir, "a.txt")) },
+			[]Status{StatusMissing, StatusMissing, StatusOK, StatusOK},
+			[]Status{StatusMissing, StatusOK, StatusOK},
+		},
+		{
+			"short file",
+			func(dir string) { os.Truncate(filepath.Join(dir, "dir", "b.bin"), 40000) },
+			[]Status{StatusOK, StatusOK, StatusShort, StatusShort},
+			[]Status{StatusOK, StatusShort, StatusOK},
+		},
+		{
+			"corrupt piece within a file",
+			func(dir string) { corrupt(t, filepath.Join(dir, "dir", "b.bin"), 60000) },
+			[]Status{StatusOK, StatusOK, StatusOK, StatusCorrupt},
+			[]Status{StatusOK, StatusCorrupt, StatusOK},
+		},
+		{
+			"corrupt piece across files",
+			func(dir string) { corrupt(t, filepath.Join(dir, "a.txt"), 40000) },
+			[]Status{StatusOK, StatusCorrupt, StatusOK, StatusOK},
+			[]Status{StatusCorrupt, StatusCorrupt, StatusOK},
+		},
+		{
+			"missing empty file",
+			func(dir string) { os.Remove(filepath.Join(dir, "dir", "empty")) },
+			[]Status{StatusOK, StatusOK, StatusOK, StatusOK},
+			[]Status{StatusOK, StatusOK, StatusMissing},
+		},
+	}
+
+	for _, test := range tests {
+		report := verifyMulti(t, test.edit)
+		if !reflect.DeepEqual(report.Pieces, test.pieces) {
+			t.Errorf("%s: Got pieces %v Wanted %v", test.name, report.Pieces, test.pieces)
+		}
+		if files := fileStatuses(report); !reflect.DeepEqual(files, test.files) {
+			t.Errorf("%s: Got files %v Wanted %v", test.name, files, test.files)
+		}
+		if wantOK := !slices.ContainsFunc(test.pieces, func(s Status) bool { return s != StatusOK }); report.OK() != wantOK {
+			t.Errorf("%s: Got OK %v Wanted %v", test.name, report.OK(), wantOK)
+		}
+	}
+
+	t.Run("Testing report details and progress", func(t *testing.T) {
+		m, err := Load("testdata/single.torrent")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		root := t.TempDir()
+		writeContent(t, filepath.Join(root, "single.bin"), 100000, 3)
+		corrupt(t, filepath.Join(root, "single.bin"), 0)
+
+		var progress []int
+		report, err := Verify(&m.Info, root, VerifyOptions{Progress: func(done, total int) {
+			if total != 4 {
+				t.Errorf("Got total %d Wanted 4", total)
+			}
+			progress = append(progress, done)
+		}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(progress, []int{1, 2, 3, 4}) {
+			t.Errorf("Got %v", progress)
+		}
+		if !reflect.DeepEqual(report.BadPieces(), []int{0}) {
+			t.Errorf("Got %v Wanted [0]", report.BadPieces())
+		}
+		f := report.Files[0]
+		if f.Path != filepath.Join(root, "single.bin") || f.Size != 100000 || f.Status != StatusCorrupt || f.Status.String() != "corrupt" {
+			t.Errorf("Got %+v", f)
+		}
+	})
+
+	t.Run("Testing padding files", func(t *testing.T) {
+		info := Info{
+			Name:        "padded",
+			PieceLength: 4,
+			Files: []FileEntry{
+				{Length: 3, Path: []string{"a"}},
+				{Length: 1, Path: []string{".pad", "1"}, Attr: "p"},
+				{Length: 2, Path: []string{"b"}},
+			},
+		}
+		for _, piece := range []string{"abc\x00", "de"} {
+			h := sha1.Sum([]byte(piece))
+			info.Pieces = append(info.Pieces, h[:]...)
+		}
+		root := t.TempDir()
+		os.MkdirAll(filepath.Join(root, "padded"), 0o755)
+		os.WriteFile(filepath.Join(root, "padded", "a"), []byte("abc"), 0o644)
+		os.WriteFile(filepath.Join(root, "padded", "b"), []byte("de"), 0o644)
+
+		report, err := Verify(&info, root, VerifyOptions{})
+		if err != nil || !report.OK() {
+			t.Errorf("Got %+v, %v", report, err)
+		}
+	})
+
+	t.Run("Testing pieces larger than the read buffer", func(t *testing.T) {
+		root := t.TempDir()
+		path := filepath.Join(root, "big.bin")
+		writeContent(t, path, 5*verifyBufferSize/2, 3)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info := &Info{Name: "big.bin", PieceLength: 2 * verifyBufferSize, Length: int64(len(data))}
+		for chunk := range slices.Chunk(data, int(info.PieceLength)) {
+			h := sha1.Sum(chunk)
+			info.Pieces = append(info.Pieces, h[:]...)
+		}
+		report, err := Verify(info, root, VerifyOptions{})
+		if err != nil || !report.OK() {
+			t.Fatalf("Got %+v, %v Wanted every piece ok", report, err)
+		}
+
+		corrupt(t, path, 3*verifyBufferSize/2)
+		report, err = Verify(info, root, VerifyOptions{})
+		if err != nil || !slices.Equal(report.BadPieces(), []int{0}) {
+			t.Errorf("Got %+v, %v Wanted piece 0 corrupt", report, err)
+		}
+	})
+
+	t.Run("Testing huge piece length", func(t *testing.T) {
+		// A piece length far beyond the content must not be allocated
+		root := t.TempDir()
+		if err := os.WriteFile(filepath.Join(root, "a"), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		h := sha1.Sum([]byte("x"))
+		info := &Info{Name: "a", PieceLength: 1 << 50, Length: 1, Pieces: h[:]}
+		report, err := Verify(info, root, VerifyOptions{})
+		if err != nil || !report.OK() {
+			t.Errorf("Got %+v, %v Wanted every piece ok", report, err)
+		}
+
+		info.PieceLength = -1
+		if _, err := Verify(info, root, VerifyOptions{}); err == nil {
+			t.Error("Error expected. Got nil")
+		}
+	})
+
+	t.Run("Testing v2-only torrent", func(t *testing.T) {
+		m, err := Load("testdata/v2.torrent")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := Verify(&m.Info, t.TempDir(), VerifyOptions{}); err == nil {
+			t.Error("Error expected. Got nil")
+		}
+	})
+}
+
+// corrupt flips the byte at offset of the file at path
+func corrupt(t *testing.T, path string, offset int64) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[offset] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,32 @@
+package parser
+
+import "unsafe"
+
+// ParseBytes parses data like Parse without first copying it into a string.
+// String values are copied out of data; use ParseBytesWithOptions with
+// ZeroCopy to have them share its memory instead.
+func ParseBytes(data []byte) (any, error) {
+	return ParseBytesWithOptions(data, Options{})
+}
+
+// ParseBytesWithOptions parses data like ParseWithOptions
+func ParseBytesWithOptions(data []byte, opts Options) (any, error) {
+	return parseAll(bytesView(data), &decodeState{opts: &opts, cloneStrings: !opts.ZeroCopy})
+}
+
+// ParseListBytes parses data like ParseList without first copying it
+func ParseListBytes(data []byte) ([]any, error) {
+	return parseList(bytesView(data), &decodeState{opts: &defaultOptions, cloneStrings: true})
+}
+
+// ParseDictionaryBytes parses data like ParseDictionary without first copying it
+func ParseDictionaryBytes(data []byte) (map[string]any, error) {
+	return parseDictionary(bytesView(data), &decodeState{opts: &defaultOptions, cloneStrings: true})
+}
+
+// bytesView returns a string sharing data's memory so that the core parser
+// can scan it without a copy. Anything retained from it after parsing must
+// go through decodeState.clone.
+func bytesView(data []byte) string {
+	return unsafe.String(unsafe.SliceData(data), len(data))
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	inputs := []string{
+		"i42e",
+		"4:spam",
+		"l4:spami42ee",
+		"d3:bar4:spam3:fooi42ee",
+		"d4:infod6:lengthi1024e4:name8:test.txtee",
+	}
+
+	for _, input := range inputs {
+		want, err := Parse(input)
+		if err != nil {
+			t.Fatalf("Parse(%q): unexpected error: %v", input, err)
+		}
+		got, err := ParseBytes([]byte(input))
+		if err != nil {
+			t.Errorf("ParseBytes(%q): unexpected error: %v", input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseBytes(%q): Got %v Wanted %v", input, got, want)
+		}
+	}
+
+	t.Run("Testing values are copied by default", func(t *testing.T) {
+		data := []byte("d3:key5:valuee")
+		dict, err := ParseDictionaryBytes(data)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		copy(data, "d3:KEY5:VALUEe")
+		if dict["key"] != "value" {
+			t.Errorf("Got %v Wanted %v", dict, map[string]any{"key": "value"})
+		}
+	})
+
+	t.Run("Testing zero-copy values alias the input", func(t *testing.T) {
+		data := []byte("l5:valuee")
+		val, err := ParseBytesWithOptions(data, Options{ZeroCopy: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		copy(data[3:], "VALUE")
+		if got := val.([]any)[0]; got != "VALUE" {
+			t.Errorf("Got %v Wanted %v", got, "VALUE")
+		}
+	})
+
+	t.Run("Testing raw values are copied by default", func(t *testing.T) {
+		data := []byte("d4:infod4:name4:testee")
+		val, err := ParseBytesWithOptions(data, Options{RawKeys: []string{"info"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		clear(data)
+		if got := val.(map[string]any)["info"]; got != RawValue("d4:name4:teste") {
+			t.Errorf("Got %v Wanted %v", got, "d4:name4:teste")
+		}
+	})
+
+	t.Run("Testing unmarshal copies strings", func(t *testing.T) {
+		data := []byte("d4:infod6:lengthi1e4:name4:testee")
+		var got testTorrent
+		if err := Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		clear(data)
+		if got.Info.Name != "test" {
+			t.Errorf("Got %v Wanted %v", got.Info.Name, "test")
+		}
+	})
+
+	t.Run("Testing errors match the string path", func(t *testing.T) {
+		_, err := ParseListBytes([]byte("li1e3abc"))
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("Expected *SyntaxError, got %v", err)
+		}
+		if syntaxErr.Offset != 4 || syntaxErr.Path != "[1]" {
+			t.Errorf("Got offset %d path %q Wanted offset 4 path \"[1]\"", syntaxErr.Offset, syntaxErr.Path)
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+)
+
+// Decoder reads and decodes bencoded values from an input stream
+type Decoder struct {
+	in   readBuffer
+	scan scanState
+	err  error
+	opts Options
+}
+
+// NewDecoder returns a decoder that reads from r.
+//
+// The decoder buffers only the value currently being decoded and may read
+// data from r beyond it; use Buffered to recover those bytes.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{in: readBuffer{r: r}}
+}
+
+// Decode reads the next bencoded value from the input and returns it in the
+// same representation as Parse. It returns io.EOF when the input ends between
+// values and io.ErrUnexpectedEOF when it ends in the middle of one.
+func (dec *Decoder) Decode() (any, error) {
+	if dec.err != nil {
+		return nil, dec.err
+	}
+
+	n, err := dec.readValue()
+	if err != nil {
+		dec.err = err
+		return nil, err
+	}
+
+	// Copy the value out of the read buffer so returned strings stay valid
+	// after the buffer is reused
+	data := string(dec.in.unread()[:n])
+	st := &decodeState{opts: &dec.opts}
+	st.prescan(data)
+	val, remaining, err := parseValue(data, st)
+	if err != nil {
+		dec.err = locateError(err, data, dec.in.offset())
+		return nil, dec.err
+	}
+
+	dec.in.pos += len(data) - len(remaining)
+	dec.scan = scanState{}
+	return val, nil
+}
+
+// Buffered returns a reader of the data remaining in the decoder's buffer
+func (dec *Decoder) Buffered() io.Reader {
+	return bytes.NewReader(dec.in.unread())
+}
+
+// InputOffset returns the number of input bytes consumed by Decode so far
+func (dec *Decoder) InputOffset() int64 {
+	return dec.in.offset()
+}
+
+// readValue reads from the underlying reader until the buffer holds a complete
+// value and returns its length. Malformed input is handed to the parser as-is
+// so that it reports the same errors as Parse.
+func (dec *Decoder) readValue() (int, error) {
+	scan := func(buf []byte) int { return dec.scan.step(buf, &dec.opts) }
+	result, err := dec.in.fill(scan, dec.opts.MaxTotalAllocation, "value")
+	switch {
+	case result == scanEnd:
+		return dec.scan.off, nil
+	case result == scanError:
+		return len(dec.in.unread()), nil
+	case err == io.EOF && len(dec.in.unread()) > 0:
+		return 0, io.ErrUnexpectedEOF
+	}
+	return 0, err
+}
+
+// readBuffer holds the input read from a stream and not yet consumed. It
+// backs the Decoder and a Tokenizer reading from an io.Reader.
+type readBuffer struct {
+	r    io.Reader
+	buf  []byte
+	pos  int   // start of the unconsumed data in buf
+	base int64 // offset of buf within the whole input
+	err  error // of the last read, io.EOF once r is exhausted
+}
+
+// unread returns the data not consumed yet
+func (b *readBuffer) unread() []byte {
+	return b.buf[b.pos:]
+}
+
+// offset returns the offset of the unconsumed data within the whole input
+func (b *readBuffer) offset() int64 {
+	return b.base + int64(b.pos)
+}
+
+// fill reads until scan no longer reports scanContinue for the unconsumed
+// data, and returns its last result along with the read error that stopped
+// it early. It refuses to buffer more than limit bytes, when positive, of a
+// single unit such as a value or token.
+func (b *readBuffer) fill(scan func([]byte) int, limit int64, unit string) (int, error) {
+	for {
+		result := scan(b.unread())
+		if result != scanContinue {
+			return result, nil
+		}
+		// Stop buffering a unit that can never fit the allocation limit
+		if limit > 0 && int64(len(b.buf)-b.pos) > limit {
+			e := limitError("", ErrAllocationLimit, "%s exceeds %d bytes", unit, limit)
+			e.Offset = b.offset() + limit
+			return result, e
+		}
+		if b.err != nil {
+			return result, b.err
+		}
+		b.read()
+	}
+}
+
+// read moves the unconsumed data to the front of the buffer and reads more
+func (b *readBuffer) read() {
+	if b.pos > 0 {
+		b.base += int64(b.pos)
+		b.buf = b.buf[:copy(b.buf, b.buf[b.pos:])]
+		b.pos = 0
+	}
+
+	const minRead = 512
+	if cap(b.buf)-len(b.buf) < minRead {
+		b.buf = append(make([]byte, 0, 2*cap(b.buf)+minRead), b.buf...)
+	}
+
+	n, err := b.r.Read(b.buf[len(b.buf):cap(b.buf)])
+	b.buf = b.buf[:len(b.buf)+n]
+	b.err = err
+}
+
+// Results of scanState.step
+const (
+	scanContinue = iota // more input is needed
+	scanEnd             // a complete value has been scanned
+	scanError           // input is malformed, let the parser report why
+)
+
+// scanState finds the end of a bencoded value in a partially filled buffer.
+// It only tracks nesting and skips strings by their declared length; full
+// validation is left to parseValue.
+type scanState struct {
+	off   int // bytes of the current value scanned so far
+	depth int // lists and dictionaries currently open
+	leaf  int // bytes of an unfinished integer at off already searched for its 'e'
+}
+
+// step resumes scanning buf, which must start at the beginning of the value.
+// Input that exceeds the depth or string length limits in opts is reported
+// as malformed, leaving the parser to produce the limit error.
+func (s *scanState) step(buf []byte, opts *Options) int {
+	for s.off < len(buf) {
+		switch c := buf[s.off]; {
+		case c == 'l' || c == 'd':
+			s.depth++
+			if s.depth > opts.maxDepth() {
+				return scanError
+			}
+			s.off++
+			continue
+
+		case c == 'e' && s.depth > 0:
+			s.depth--
+			s.off++
+
+		default:
+			n, result := scanLeaf(buf[s.off:], opts, &s.leaf)
+			if result != scanEnd {
+				return result
+			}
+			s.off += n
+		}
+
+		if s.depth == 0 {
+			return scanEnd
+		}
+	}
+	return scanContinue
+}
+
+// scanLeaf finds the end of the integer or string at the start of buf and
+// returns its length once buf holds all of it. scanned holds how much of an
+// unfinished integer earlier calls searched, so that each call only looks
+// at new input; it is reset once the integer ends.
+func scanLeaf(buf []byte, opts *Options, scanned *int) (int, int) {
+	if buf[0] == 'i' {
+		eIndex := bytes.IndexByte(buf[*scanned:], 'e')
+		if eIndex == -1 {
+			*scanned = len(buf)
+			return 0, scanContinue
+		}
+		n := *scanned + eIndex + 1
+		*scanned = 0
+		return n, scanEnd
+	}
+
+	// Must be a string, mirror parseValue's length rules. The search for
+	// the colon is bounded by maxLengthPrefix.
+	colonIndex := bytes.IndexByte(buf[:min(len(buf), maxLengthPrefix+1)], ':')
+	if colonIndex == -1 {
+		if len(buf) > maxLengthPrefix || !isLengthPrefix(buf) {
+			return 0, scanError
+		}
+		return 0, scanContinue
+	}
+	length, err := strconv.Atoi(string(buf[:colonIndex]))
+	if err != nil || length < 0 || (opts.MaxStringLength > 0 && length > opts.MaxStringLength) {
+		return 0, scanError
+	}
+	start := colonIndex + 1
+	if length > len(buf)-start {
+		return 0, scanContinue
+	}
+	return start + length, scanEnd
+}
+
+// scanToken reports whether buf starts with a complete token, resuming the
+// search of an unfinished integer as scanLeaf does
+func scanToken(buf []byte, opts *Options, scanned *int) int {
+	if len(buf) == 0 {
+		return scanContinue
+	}
+	if buf[0] == 'l' || buf[0] == 'd' || buf[0] == 'e' {
+		return scanEnd
+	}
+	_, result := scanLeaf(buf, opts, scanned)
+	return result
+}
+
+// isLengthPrefix reports whether b could still be the start of a string
+// length that strconv.Atoi accepts, so that it is worth waiting for its colon
+func isLengthPrefix(b []byte) bool {
+	if len(b) > 0 && (b[0] == '+' || b[0] == '-') {
+		b = b[1:]
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
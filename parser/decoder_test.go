@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder(t *testing.T) {
+	t.Run("Testing back-to-back values", func(t *testing.T) {
+		input := "i42e4:spamli1ei2eed3:foo3:bare"
+		want := []any{
+			int64(42),
+			"spam",
+			[]any{int64(1), int64(2)},
+			map[string]any{"foo": "bar"},
+		}
+
+		// OneByteReader forces the decoder to resume scanning after every byte
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+		for i, w := range want {
+			got, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("Decode #%d: unexpected error: %v", i, err)
+			}
+			if !reflect.DeepEqual(got, w) {
+				t.Errorf("Decode #%d: Got %v Wanted %v", i, got, w)
+			}
+		}
+
+		if _, err := dec.Decode(); err != io.EOF {
+			t.Errorf("Expected io.EOF after last value, got %v", err)
+		}
+		if dec.InputOffset() != int64(len(input)) {
+			t.Errorf("InputOffset() = %d, want %d", dec.InputOffset(), len(input))
+		}
+	})
+
+	t.Run("Testing large string split across reads", func(t *testing.T) {
+		payload := strings.Repeat("x", 10000)
+		input := "d6:pieces10000:" + payload + "e"
+
+		dec := NewDecoder(iotest.HalfReader(strings.NewReader(input)))
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := map[string]any{"pieces": payload}
+		if !reflect.DeepEqual(got, want) {
+			t.Error("Decoded dictionary does not match input")
+		}
+	})
+
+	t.Run("Testing truncated value", func(t *testing.T) {
+		dec := NewDecoder(strings.NewReader("li1ei2e"))
+		if _, err := dec.Decode(); err != io.ErrUnexpectedEOF {
+			t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+		}
+	})
+
+	t.Run("Testing malformed value", func(t *testing.T) {
+		dec := NewDecoder(strings.NewReader("li1ex2:abe"))
+		if _, err := dec.Decode(); err == nil {
+			t.Error("Expected error for malformed list, got nil")
+		}
+	})
+
+	t.Run("Testing long length prefix", func(t *testing.T) {
+		// The prefix can never fit an int, so the decoder must give up on it
+		// rather than buffer the endless run of digits
+		r := &countingReader{r: iotest.OneByteReader(repeatReader("1"))}
+		dec := NewDecoder(r)
+		if _, err := dec.Decode(); err == nil {
+			t.Error("Expected error for endless length prefix, got nil")
+		}
+		if r.n > maxLengthPrefix+1 {
+			t.Errorf("Got %d bytes read Wanted at most %d", r.n, maxLengthPrefix+1)
+		}
+	})
+
+	t.Run("Testing long integer split across reads", func(t *testing.T) {
+		// Resuming the search for the 'e' keeps a one byte at a time stream
+		// linear, so the whole integer reaches the parser
+		input := "i" + strings.Repeat("1", 1<<20) + "e"
+
+		dec := NewDecoder(iotest.OneByteReader(strings.NewReader(input)))
+		if _, err := dec.Decode(); !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("Got %v Wanted %v", err, strconv.ErrRange)
+		}
+	})
+
+	t.Run("Testing read error", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		dec := NewDecoder(iotest.ErrReader(readErr))
+		if _, err := dec.Decode(); err != readErr {
+			t.Errorf("Expected %v, got %v", readErr, err)
+		}
+	})
+
+	t.Run("Testing Buffered after value", func(t *testing.T) {
+		dec := NewDecoder(strings.NewReader("d1:ai1ee<raw piece data>"))
+		if _, err := dec.Decode(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		rest, _ := io.ReadAll(dec.Buffered())
+		if string(rest) != "<raw piece data>" {
+			t.Errorf("Buffered() = %q, want %q", rest, "<raw piece data>")
+		}
+	})
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
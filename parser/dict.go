@@ -0,0 +1,34 @@
+package parser
+
+// Dict is a dictionary that keeps its entries in the order they appeared in
+// the input, including duplicate keys. Parsing with Options.OrderedDicts
+// produces Dict values instead of map[string]any, and the encoder writes a
+// Dict's entries in that same order, so non-canonical dictionaries survive a
+// round trip unchanged.
+type Dict []DictEntry
+
+// DictEntry is a single key/value pair of a Dict
+type DictEntry struct {
+	Key   string
+	Value any
+}
+
+// Get returns the value for key. When the key occurs more than once the last
+// entry wins, as it does in the map Parse builds.
+func (d Dict) Get(key string) (any, bool) {
+	for i := len(d) - 1; i >= 0; i-- {
+		if d[i].Key == key {
+			return d[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the keys of d in order
+func (d Dict) Keys() []string {
+	keys := make([]string, len(d))
+	for i, entry := range d {
+		keys[i] = entry.Key
+	}
+	return keys
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrderedDicts(t *testing.T) {
+	t.Run("Testing input order is kept", func(t *testing.T) {
+		got, err := ParseWithOptions("d3:fooi1e3:barl3:bazd1:zi1e1:ai2eee1:bi3ee", Options{OrderedDicts: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := Dict{
+			{Key: "foo", Value: int64(1)},
+			{Key: "bar", Value: []any{"baz", Dict{{Key: "z", Value: int64(1)}, {Key: "a", Value: int64(2)}}}},
+			{Key: "b", Value: int64(3)},
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Got %v Wanted %v", got, expected)
+		}
+	})
+
+	t.Run("Testing lookup", func(t *testing.T) {
+		got, err := ParseWithOptions("d1:bi1e1:ai2e1:bi3ee", Options{OrderedDicts: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		dict := got.(Dict)
+
+		if value, ok := dict.Get("b"); !ok || value != int64(3) {
+			t.Errorf("Got %v, %v Wanted %v, true", value, ok, 3)
+		}
+		if _, ok := dict.Get("c"); ok {
+			t.Errorf("Got a value for a missing key")
+		}
+		if keys := dict.Keys(); !reflect.DeepEqual(keys, []string{"b", "a", "b"}) {
+			t.Errorf("Got %v Wanted %v", keys, []string{"b", "a", "b"})
+		}
+	})
+
+	t.Run("Testing empty dictionaries", func(t *testing.T) {
+		got, err := ParseWithOptions("de", Options{OrderedDicts: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if dict, ok := got.(Dict); !ok || len(dict) != 0 {
+			t.Errorf("Got %#v Wanted an empty Dict", got)
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// ErrNotFound is returned by Document.Get when a path does not exist
+var ErrNotFound = errors.New("path not found")
+
+// Document gives on-demand access to the values of a bencoded document.
+//
+// Nothing is decoded. The first query validates the document once, noting
+// where each list and dictionary ends. A query through a container then
+// records where each of its elements begins and ends, stepping over nested
+// containers by their noted ends, and later queries reuse that index. So
+// fetching info.name from a torrent never decodes its pieces or file list,
+// and no value is scanned more than once per query. Malformed input is
+// reported by the first query. A Document is safe for concurrent use.
+type Document struct {
+	input string
+	opts  Options
+
+	mu    sync.Mutex
+	nodes map[int]*docNode // lists and dictionaries indexed so far, by offset
+	ends  map[int]int      // offset just past every list and dictionary walked so far, by offset
+}
+
+// docNode indexes the elements of a list or the values of a dictionary
+type docNode struct {
+	keys     map[string]int // dictionary key to index in children, nil for lists
+	children []span
+	end      int // offset just past the closing 'e'
+}
+
+// span is the extent of a value in the input
+type span struct {
+	start, end int
+}
+
+// NewDocument returns a document over the bencoded value in str
+func NewDocument(str string) *Document {
+	return NewDocumentWithOptions(str, Options{})
+}
+
+// NewDocumentBytes returns a document over data without copying it. Values
+// returned by Get share data's memory and are only valid while it is
+// unmodified.
+func NewDocumentBytes(data []byte) *Document {
+	return NewDocument(bytesView(data))
+}
+
+// Get returns the raw value at path, where each element is a string key
+// selecting a dictionary entry or an int index selecting a list element.
+// For example Get("info", "files", 2, "length") returns the length of the
+// third file of a torrent, and Get() returns the whole document. Missing
+// keys, out of range indexes and paths through non-containers yield an
+// error wrapping ErrNotFound; malformed input yields a *SyntaxError.
+func (d *Document) Get(path ...any) (RawValue, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	root, err := d.root()
+	if err != nil {
+		return "", err
+	}
+
+	current := root
+	var at string
+	for _, segment := range path {
+		parent := at
+		var want byte
+		switch segment := segment.(type) {
+		case string:
+			at, want = appendPath(at, segment), 'd'
+		case int:
+			at, want = appendPath(at, "["+strconv.Itoa(segment)+"]"), 'l'
+		default:
+			return "", fmt.Errorf("invalid path element %v of type %T", segment, segment)
+		}
+		if d.input[current.start] != want {
+			return "", fmt.Errorf("%w: %s (parent is %s)", ErrNotFound, at, kindOf(d.input[current.start:]))
+		}
+
+		node, err := d.index(current.start, parent)
+		if err != nil {
+			return "", err
+		}
+		if key, ok := segment.(string); ok {
+			i, ok := node.keys[key]
+			if !ok {
+				return "", fmt.Errorf("%w: %s", ErrNotFound, at)
+			}
+			current = node.children[i]
+		} else {
+			i := segment.(int)
+			if i < 0 || i >= len(node.children) {
+				return "", fmt.Errorf("%w: %s (list has %d elements)", ErrNotFound, at, len(node.children))
+			}
+			current = node.children[i]
+		}
+	}
+
+	return RawValue(d.input[current.start:current.end]), nil
+}
+
+// appendPath extends the error path at with a key or "[index]" segment
+func appendPath(at, segment string) string {
+	if at == "" {
+		return segment
+	}
+	return joinPath(at, segment)
+}
+
+// root returns the extent of the top-level value, checking that nothing
+// follows it
+func (d *Document) root() (span, error) {
+	var end int
+	switch {
+	case len(d.input) > 0 && (d.input[0] == 'l' || d.input[0] == 'd'):
+		node, err := d.index(0, "")
+		if err != nil {
+			return span{}, err
+		}
+		end = node.end
+	default:
+		remaining, err := skipValue(d.input, &decodeState{opts: &d.opts})
+		if err != nil {
+			return span{}, locateError(err, d.input, 0)
+		}
+		end = len(d.input) - len(remaining)
+	}
+
+	if end < len(d.input) {
+		return span{}, locateError(syntaxError(d.input, end, "end of input", "extra data after value"), d.input, 0)
+	}
+	return span{0, end}, nil
+}
+
+// index returns the index of the list or dictionary at offset start, whose
+// key path is at, building it on first use
+func (d *Document) index(start int, at string) (*docNode, error) {
+	if node, ok := d.nodes[start]; ok {
+		return node, nil
+	}
+
+	node, err := d.indexContainer(start, &decodeState{opts: &d.opts}, true)
+	if err != nil {
+		if at != "" {
+			err = prefixPath(err, at)
+		}
+		return nil, locateError(err, d.input, 0)
+	}
+	d.nodes[start] = node
+	return node, nil
+}
+
+// indexContainer validates the list or dictionary at offset start without
+// decoding it and notes its end. With keep set, it also returns the extent
+// of every element. Nested containers are walked on first sight and stepped
+// over by their noted end afterwards.
+func (d *Document) indexContainer(start int, st *decodeState, keep bool) (*docNode, error) {
+	node := &docNode{}
+	if d.input[start] == 'd' && keep {
+		node.keys = make(map[string]int)
+	}
+
+	remaining, err := walkContainer(d.input[start:], st, 0, func(current, key string, _ int) (string, error) {
+		valueStart := len(d.input) - len(current)
+		valueEnd, err := d.skip(valueStart, st)
+		if err != nil {
+			return "", err
+		}
+		if keep {
+			if node.keys != nil {
+				node.keys[key] = len(node.children)
+			}
+			node.children = append(node.children, span{valueStart, valueEnd})
+		}
+		return d.input[valueEnd:], nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	node.end = len(d.input) - len(remaining)
+	d.ends[start] = node.end
+	return node, nil
+}
+
+// skip validates the value at offset start and returns the offset just past
+// it, stepping over a list or dictionary whose end is already noted
+func (d *Document) skip(start int, st *decodeState) (int, error) {
+	s := d.input[start:]
+	if len(s) == 0 || (s[0] != 'l' && s[0] != 'd') {
+		remaining, err := skipValue(s, st)
+		return len(d.input) - len(remaining), err
+	}
+	if end, ok := d.ends[start]; ok {
+		return end, nil
+	}
+	node, err := d.indexContainer(start, st, false)
+	if err != nil {
+		return 0, err
+	}
+	return node.end, nil
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const documentTorrent = "d8:announce8:test.com4:infod5:filesld6:lengthi1e4:pathl1:aeed6:lengthi2e4:pathl1:beed6:lengthi3e4:pathl1:cee" +
+	"e4:name4:test6:pieces4:abcdee"
+
+func TestDocument(t *testing.T) {
+	doc := NewDocument(documentTorrent)
+
+	tests := []struct {
+		path     []any
+		expected RawValue
+	}{
+		{[]any{"announce"}, "8:test.com"},
+		{[]any{"info", "name"}, "4:test"},
+		{[]any{"info", "files", 2, "length"}, "i3e"},
+		{[]any{"info", "files", 0, "path", 0}, "1:a"},
+		{[]any{"info", "files", 1}, "d6:lengthi2e4:pathl1:bee"},
+		{nil, RawValue(documentTorrent)},
+	}
+
+	for _, test := range tests {
+		got, err := doc.Get(test.path...)
+		if err != nil {
+			t.Errorf("Get(%v): unexpected error: %v", test.path, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("Get(%v): Got %v Wanted %v", test.path, got, test.expected)
+		}
+	}
+
+	t.Run("Testing missing paths", func(t *testing.T) {
+		tests := []struct {
+			path     []any
+			expected string
+		}{
+			{[]any{"comment"}, "path not found: comment"},
+			{[]any{"info", "files", 3}, "path not found: info.files[3] (list has 3 elements)"},
+			{[]any{"info", "files", -1}, "path not found: info.files[-1] (list has 3 elements)"},
+			{[]any{"info", "name", "x"}, "path not found: info.name.x (parent is string)"},
+			{[]any{"info", 0}, "path not found: info[0] (parent is dictionary)"},
+			{[]any{"announce", 0}, "path not found: announce[0] (parent is string)"},
+			{[]any{0}, "path not found: [0] (parent is dictionary)"},
+		}
+
+		for _, test := range tests {
+			_, err := doc.Get(test.path...)
+			if !errors.Is(err, ErrNotFound) || err.Error() != test.expected {
+				t.Errorf("Get(%v): Got %v Wanted %v", test.path, err, test.expected)
+			}
+		}
+	})
+
+	t.Run("Testing invalid path elements", func(t *testing.T) {
+		if _, err := doc.Get("info", 1.5); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Got %v Wanted an invalid path error", err)
+		}
+	})
+
+	t.Run("Testing leaf documents", func(t *testing.T) {
+		got, err := NewDocumentBytes([]byte("i42e")).Get()
+		if err != nil || got != "i42e" {
+			t.Errorf("Got %v, %v Wanted i42e", got, err)
+		}
+	})
+
+	t.Run("Testing malformed input", func(t *testing.T) {
+		tests := []struct {
+			input   string
+			path    []any
+			offset  int64
+			errPath string
+		}{
+			{"d1:ai1e1:bli01eee", []any{"a"}, 12, "b[0]"},
+			{"d1:ai1ee4:spam", []any{"a"}, 8, ""},
+			{"d1:ai1e", []any{"a"}, 7, ""},
+			{"i1x", nil, 0, ""},
+		}
+
+		for _, test := range tests {
+			_, err := NewDocument(test.input).Get(test.path...)
+
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("%q: expected *SyntaxError, got %v", test.input, err)
+				continue
+			}
+			if syntaxErr.Offset != test.offset || syntaxErr.Path != test.errPath {
+				t.Errorf("%q: Got offset %d path %q Wanted offset %d path %q", test.input, syntaxErr.Offset, syntaxErr.Path, test.offset, test.errPath)
+			}
+		}
+	})
+
+	t.Run("Testing options", func(t *testing.T) {
+		input := "d1:bi1e1:ai2ee"
+		if got, err := NewDocument(input).Get("a"); err != nil || got != "i2e" {
+			t.Errorf("Got %v, %v Wanted i2e", got, err)
+		}
+
+		_, err := NewDocumentWithOptions(input, Options{Strict: true}).Get("a")
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Offset != 7 {
+			t.Errorf("Got %v Wanted unsorted key error at offset 7", err)
+		}
+
+		_, err = NewDocumentWithOptions("d1:ali1ei2ei3eee", Options{MaxListLength: 2}).Get()
+		if !errors.Is(err, ErrListTooLong) {
+			t.Errorf("Got %v Wanted %v", err, ErrListTooLong)
+		}
+	})
+
+	t.Run("Testing large strings are skipped", func(t *testing.T) {
+		input := "d4:infod4:name4:test6:pieces1000000:" + strings.Repeat("x", 1000000) + "ee"
+		got, err := NewDocument(input).Get("info", "name")
+		if err != nil || got != "4:test" {
+			t.Errorf("Got %v, %v Wanted 4:test", got, err)
+		}
+	})
+
+	t.Run("Testing deep paths walk each container once", func(t *testing.T) {
+		const depth = 5000
+		input := strings.Repeat("l", depth) + "i7e" + strings.Repeat("e", depth)
+		doc := NewDocument(input)
+		path := make([]any, depth)
+		for i := range path {
+			path[i] = 0
+		}
+
+		got, err := doc.Get(path...)
+		if err != nil || got != "i7e" {
+			t.Fatalf("Got %v, %v Wanted i7e", got, err)
+		}
+		if len(doc.ends) != depth || len(doc.nodes) != depth {
+			t.Errorf("Got %d ends and %d nodes Wanted %d", len(doc.ends), len(doc.nodes), depth)
+		}
+		for start, end := range doc.ends {
+			if end != len(input)-start {
+				t.Errorf("List at %d: Got end %d Wanted %d", start, end, len(input)-start)
+				break
+			}
+		}
+	})
+}
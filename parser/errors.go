@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SyntaxError describes malformed bencode input. It is returned by every
+// parsing entry point and can be matched with errors.As.
+type SyntaxError struct {
+	Offset   int64  // byte offset of the error from the start of the input
+	Path     string // key path of the enclosing value, e.g. "info.files[3].length"
+	Expected string // token the parser expected at Offset, if any
+	Context  string // short excerpt of the input around Offset
+	Err      error  // underlying cause, such as strconv.ErrRange, if any
+
+	msg string // description of the error
+	rem int    // bytes from Offset to the end of the input being parsed
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s at offset %d", e.msg, e.Offset)
+	}
+	return fmt.Sprintf("%s at offset %d in %s", e.msg, e.Offset, e.Path)
+}
+
+// Unwrap returns the underlying cause of the error
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Bounds for the excerpts embedded in errors, so that a malformed
+// multi-megabyte pieces field doesn't end up in an error message
+const (
+	contextBefore = 8
+	contextAfter  = 24
+	maxQuoted     = 32
+)
+
+// syntaxError reports an error at index i of s, where s is the unparsed
+// remainder of the input. Its Offset is resolved by locateError once the
+// error reaches an entry point that knows where the input starts.
+func syntaxError(s string, i int, expected, format string, args ...any) *SyntaxError {
+	start := max(0, i-contextBefore)
+	end := min(len(s), i+contextAfter)
+	return &SyntaxError{
+		Expected: expected,
+		Context:  strings.Clone(s[start:end]), // Don't pin the whole input in memory
+		msg:      fmt.Sprintf(format, args...),
+		rem:      len(s) - i,
+	}
+}
+
+// locateError sets the absolute Offset of a *SyntaxError raised while parsing
+// input, which begins base bytes into the stream
+func locateError(err error, input string, base int64) error {
+	if e, ok := err.(*SyntaxError); ok {
+		e.Offset = base + int64(len(input)-e.rem)
+	}
+	return err
+}
+
+// prefixPath prepends a key path segment to errors that carry a path. Paths
+// are built while unwinding so that successful decodes pay nothing for them.
+func prefixPath(err error, segment string) error {
+	switch e := err.(type) {
+	case *SyntaxError:
+		e.Path = joinPath(segment, e.Path)
+	case *UnmarshalTypeError:
+		e.Path = joinPath(segment, e.Path)
+	}
+	return err
+}
+
+func joinPath(segment, rest string) string {
+	if rest == "" {
+		return segment
+	}
+	if rest[0] == '[' {
+		return segment + rest
+	}
+	return segment + "." + rest
+}
+
+// quote formats an input fragment for an error message, truncating long ones
+func quote(s string) string {
+	if len(s) > maxQuoted {
+		return fmt.Sprintf("%q...", s[:maxQuoted])
+	}
+	return fmt.Sprintf("%q", s)
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSyntaxError(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		offset   int64
+		path     string
+		expected string
+	}{
+		{"bad integer", "iabce", 1, "", "digit"},
+		{"missing colon", "li1e3abc", 4, "[1]", "':'"},
+		{"nested path", "d4:infod5:filesld6:lengthi1eed6:lengthi01eeeee", 39, "info.files[1].length", "digit"},
+		{"truncated string", "d6:pieces100:abc", 13, "pieces", "string data"},
+		{"unterminated dictionary", "d3:foo3:bar", 11, "", "'e'"},
+		{"extra data", "i1ei2e", 3, "", "end of input"},
+		{"non-string key", "di42e3:bare", 1, "", "string key"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.input)
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%s): expected *SyntaxError, got %v", test.name, err)
+			continue
+		}
+		if syntaxErr.Offset != test.offset {
+			t.Errorf("Parse(%s): Got offset %d Wanted %d", test.name, syntaxErr.Offset, test.offset)
+		}
+		if syntaxErr.Path != test.path {
+			t.Errorf("Parse(%s): Got path %q Wanted %q", test.name, syntaxErr.Path, test.path)
+		}
+		if syntaxErr.Expected != test.expected {
+			t.Errorf("Parse(%s): Got expected %q Wanted %q", test.name, syntaxErr.Expected, test.expected)
+		}
+	}
+
+	t.Run("Testing error size is bounded for huge input", func(t *testing.T) {
+		pieces := strings.Repeat("x", 1<<20)
+		input := "d6:pieces2000000:" + pieces + "e"
+
+		_, err := Parse(input)
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("Expected *SyntaxError, got %v", err)
+		}
+		if len(err.Error()) > 200 || len(syntaxErr.Context) > contextBefore+contextAfter {
+			t.Errorf("Error message or context too large: %d / %d bytes", len(err.Error()), len(syntaxErr.Context))
+		}
+	})
+
+	t.Run("Testing offsets across decoded stream values", func(t *testing.T) {
+		dec := NewDecoder(strings.NewReader("i1e4:spamli1ei-0x1ee"))
+		for i := 0; i < 2; i++ {
+			if _, err := dec.Decode(); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+
+		_, err := dec.Decode()
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("Expected *SyntaxError, got %v", err)
+		}
+		if syntaxErr.Offset != 14 || syntaxErr.Path != "[1]" {
+			t.Errorf("Got offset %d path %q Wanted offset 14 path \"[1]\"", syntaxErr.Offset, syntaxErr.Path)
+		}
+	})
+
+	t.Run("Testing unmarshal syntax errors", func(t *testing.T) {
+		var got testTorrent
+		err := Unmarshal([]byte("d4:infod4:name4:test"), &got)
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("Expected *SyntaxError, got %v", err)
+		}
+		if syntaxErr.Offset != 20 || syntaxErr.Path != "info" {
+			t.Errorf("Got offset %d path %q Wanted offset 20 path \"info\"", syntaxErr.Offset, syntaxErr.Path)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds are added to every parser fuzz target on top of testdata/fuzz
+var fuzzSeeds = []string{
+	"i0e",
+	"i-42e",
+	"i9223372036854775807e",
+	"i9223372036854775808e",
+	"4:spam",
+	"0:",
+	"le",
+	"de",
+	"l4:spami42ee",
+	"d3:bar4:spam3:fooi42ee",
+	"d4:infod5:filesld6:lengthi1e4:pathl1:aeee4:name4:test12:piece lengthi16e6:pieces0:ee",
+	"9223372036854775807:x",
+	"i-0e",
+	"01:a",
+	"lllllleeeeee",
+}
+
+// checkSyntaxError fails unless err is a *SyntaxError located inside input
+func checkSyntaxError(t *testing.T, input string, err error) {
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("%q: error is not a *SyntaxError: %v", input, err)
+	}
+	if syntaxErr.Offset < 0 || syntaxErr.Offset > int64(len(input)) {
+		t.Fatalf("%q: error offset %d outside input of %d bytes", input, syntaxErr.Offset, len(input))
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		val, err := Parse(input)
+		if err != nil {
+			checkSyntaxError(t, input, err)
+		}
+
+		// Every entry point must agree with Parse about what is valid
+		bytesVal, bytesErr := ParseBytes([]byte(input))
+		if (bytesErr == nil) != (err == nil) || !reflect.DeepEqual(bytesVal, val) {
+			t.Fatalf("%q: ParseBytes = %v, %v; Parse = %v, %v", input, bytesVal, bytesErr, val, err)
+		}
+
+		scanned, scanErr := ParseWithOptions(input, Options{PreScan: true})
+		if (scanErr == nil) != (err == nil) || !reflect.DeepEqual(scanned, val) {
+			t.Fatalf("%q: PreScan = %v, %v; Parse = %v, %v", input, scanned, scanErr, val, err)
+		}
+
+		if _, tokErr := tokenize(input); (tokErr == nil) != (err == nil) {
+			t.Fatalf("%q: Tokenizer error %v; Parse error %v", input, tokErr, err)
+		}
+
+		if _, docErr := NewDocument(input).Get(); (docErr == nil) != (err == nil) {
+			t.Fatalf("%q: Document error %v; Parse error %v", input, docErr, err)
+		}
+
+		if err == nil {
+			decoded, decErr := NewDecoder(strings.NewReader(input)).Decode()
+			if decErr != nil || !reflect.DeepEqual(decoded, val) {
+				t.Fatalf("%q: Decode = %v, %v; Parse = %v", input, decoded, decErr, val)
+			}
+		}
+
+		// The strict parser must never accept what Parse rejects
+		if _, strictErr := ParseStrict(input); strictErr == nil && err != nil {
+			t.Fatalf("%q: ParseStrict accepted input Parse rejected: %v", input, err)
+		}
+	})
+}
+
+func FuzzParseInteger(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		n, err := ParseInteger(input)
+		if err != nil {
+			checkSyntaxError(t, input, err)
+			return
+		}
+
+		val, parseErr := Parse(input)
+		if parseErr != nil || val != int64(n) {
+			t.Fatalf("%q: ParseInteger = %d; Parse = %v, %v", input, n, val, parseErr)
+		}
+		if canonical := "i" + strconv.Itoa(n) + "e"; input != canonical {
+			if _, strictErr := ParseStrict(input); strictErr == nil {
+				t.Fatalf("%q: ParseStrict accepted non-canonical form of %s", input, canonical)
+			}
+		}
+	})
+}
+
+func FuzzParseString(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		s, err := ParseString(input)
+		if err != nil {
+			checkSyntaxError(t, input, err)
+			return
+		}
+
+		// ParseString is stricter than Parse about length prefixes, never looser
+		val, parseErr := Parse(input)
+		if parseErr != nil || val != s {
+			t.Fatalf("%q: ParseString = %q; Parse = %v, %v", input, s, val, parseErr)
+		}
+	})
+}
+
+func FuzzTokenizer(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		tok := NewTokenizer(input)
+		var prevEnd int64
+		for {
+			token, err := tok.Next()
+			if err == io.EOF {
+				if prevEnd != int64(len(input)) {
+					t.Fatalf("%q: tokens end at %d", input, prevEnd)
+				}
+				return
+			}
+			if err != nil {
+				checkSyntaxError(t, input, err)
+				return
+			}
+			if token.Offset != prevEnd || token.End <= token.Offset || token.End > int64(len(input)) {
+				t.Fatalf("%q: token %+v does not follow offset %d", input, token, prevEnd)
+			}
+			prevEnd = token.End
+		}
+	})
+}
+
+func FuzzTokenizerReader(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		tokens, err := tokenize(input)
+		readerTokens, readerErr := tokenizeReader(input)
+		if !reflect.DeepEqual(tokens, readerTokens) || (err == nil) != (readerErr == nil) {
+			t.Fatalf("%q: Got %v, %v Wanted %v, %v", input, readerTokens, readerErr, tokens, err)
+		}
+		var want, got *SyntaxError
+		if errors.As(err, &want) && (!errors.As(readerErr, &got) || got.Offset != want.Offset || got.Path != want.Path) {
+			t.Fatalf("%q: Got %v Wanted %v", input, readerErr, err)
+		}
+	})
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var torrent testTorrent
+		Unmarshal(data, &torrent)
+
+		var v any
+		err := Unmarshal(data, &v)
+		val, parseErr := ParseBytes(data)
+		if (err == nil) != (parseErr == nil) || (err == nil && !reflect.DeepEqual(v, val)) {
+			t.Fatalf("%q: Unmarshal = %v, %v; Parse = %v, %v", data, v, err, val, parseErr)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"errors"
+	"iter"
+)
+
+// Entries returns an iterator over the keys and values of the dictionary r.
+// Each entry is parsed only when the loop reaches it, and its value is
+// returned undecoded, so large dictionaries can be walked without decoding
+// them up front:
+//
+//	var err error
+//	for key, value := range info.Entries(&err) {
+//		...
+//	}
+//	if err != nil {
+//		...
+//	}
+//
+// If r is not a well-formed dictionary, iteration stops at the problem and
+// the *SyntaxError is stored in *errp, with offsets relative to the start of
+// r. errp may be nil to ignore errors.
+func (r RawValue) Entries(errp *error) iter.Seq2[string, RawValue] {
+	return r.EntriesWithOptions(Options{}, errp)
+}
+
+// EntriesWithOptions is Entries using the given options to validate r
+func (r RawValue) EntriesWithOptions(opts Options, errp *error) iter.Seq2[string, RawValue] {
+	return func(yield func(string, RawValue) bool) {
+		r.iterate('d', &opts, errp, yield)
+	}
+}
+
+// Elements returns an iterator over the elements of the list r, each
+// returned undecoded and parsed only when the loop reaches it. Errors are
+// reported through errp as for Entries.
+func (r RawValue) Elements(errp *error) iter.Seq[RawValue] {
+	return r.ElementsWithOptions(Options{}, errp)
+}
+
+// ElementsWithOptions is Elements using the given options to validate r
+func (r RawValue) ElementsWithOptions(opts Options, errp *error) iter.Seq[RawValue] {
+	return func(yield func(RawValue) bool) {
+		r.iterate('l', &opts, errp, func(_ string, value RawValue) bool {
+			return yield(value)
+		})
+	}
+}
+
+// errStopped ends a walk when the loop over an iterator breaks
+var errStopped = errors.New("iteration stopped")
+
+// iterate walks r, which must be a list or dictionary as selected by kind,
+// calling yield with each key, empty in a list, and undecoded value
+func (r RawValue) iterate(kind byte, opts *Options, errp *error, yield func(string, RawValue) bool) {
+	s := string(r)
+	name := kindOf(string(kind))
+	err := func() error {
+		if len(s) == 0 || s[0] != kind {
+			return syntaxError(s, 0, name, "input was not a bencoded %s", name)
+		}
+		st := &decodeState{opts: opts}
+		remaining, err := walkContainer(s, st, 0, func(current, key string, _ int) (string, error) {
+			remaining, err := skipValue(current, st)
+			if err != nil {
+				return "", err
+			}
+			if !yield(key, RawValue(current[:len(current)-len(remaining)])) {
+				return "", errStopped
+			}
+			return remaining, nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(remaining) > 0 {
+			return syntaxError(remaining, 0, "end of input", "extra data after %s", name)
+		}
+		return nil
+	}()
+	if err != nil && !errors.Is(err, errStopped) && errp != nil {
+		*errp = locateError(err, s, 0)
+	}
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	t.Run("Testing dictionary entries", func(t *testing.T) {
+		var err error
+		var keys []string
+		var values []RawValue
+		for key, value := range RawValue("d4:infod4:name4:teste3:numi42e4:spaml1:aee").Entries(&err) {
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedKeys := []string{"info", "num", "spam"}
+		expectedValues := []RawValue{"d4:name4:teste", "i42e", "l1:ae"}
+		if len(keys) != len(expectedKeys) {
+			t.Fatalf("Got %v Wanted %v", keys, expectedKeys)
+		}
+		for i := range keys {
+			if keys[i] != expectedKeys[i] || values[i] != expectedValues[i] {
+				t.Errorf("Got %s=%s Wanted %s=%s", keys[i], values[i], expectedKeys[i], expectedValues[i])
+			}
+		}
+	})
+
+	t.Run("Testing early break stops parsing", func(t *testing.T) {
+		var err error
+		for key := range RawValue("d1:ai1e1:bi01xe").Entries(&err) {
+			if key == "a" {
+				break
+			}
+		}
+		if err != nil {
+			t.Errorf("Got %v Wanted no error", err)
+		}
+	})
+
+	t.Run("Testing malformed dictionaries", func(t *testing.T) {
+		tests := []struct {
+			input  string
+			offset int64
+			path   string
+		}{
+			{"l1:ae", 0, ""},
+			{"d1:ai1x", 4, "a"},
+			{"di1ei2ee", 1, ""},
+			{"d1:a", 4, ""},
+			{"d1:ai1e", 7, ""},
+			{"d1:ai1eei2e", 8, ""},
+		}
+
+		for _, test := range tests {
+			var err error
+			for range RawValue(test.input).Entries(&err) {
+			}
+
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("%q: expected *SyntaxError, got %v", test.input, err)
+				continue
+			}
+			if syntaxErr.Offset != test.offset || syntaxErr.Path != test.path {
+				t.Errorf("%q: Got offset %d path %q Wanted offset %d path %q", test.input, syntaxErr.Offset, syntaxErr.Path, test.offset, test.path)
+			}
+		}
+	})
+}
+
+func TestElements(t *testing.T) {
+	t.Run("Testing nested iteration", func(t *testing.T) {
+		info := RawValue("d5:filesld6:lengthi1eed6:lengthi2eeee")
+
+		var err error
+		var total int64
+		for key, files := range info.Entries(&err) {
+			if key != "files" {
+				continue
+			}
+			for file := range files.Elements(&err) {
+				for key, length := range file.Entries(&err) {
+					if key == "length" {
+						n, _ := ParseInteger(string(length))
+						total += int64(n)
+					}
+				}
+			}
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if total != 3 {
+			t.Errorf("Got %v Wanted %v", total, 3)
+		}
+	})
+
+	t.Run("Testing options", func(t *testing.T) {
+		var err error
+		var keys []string
+		for key := range RawValue("d1:ai1e1:ci2e1:bi3ee").EntriesWithOptions(Options{Strict: true}, &err) {
+			keys = append(keys, key)
+		}
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Offset != 13 || len(keys) != 2 {
+			t.Errorf("Got %v after %v Wanted unsorted key error at offset 13 after [a c]", err, keys)
+		}
+
+		err = nil
+		var count int
+		for range RawValue("li1ei2ei3ee").ElementsWithOptions(Options{MaxListLength: 2}, &err) {
+			count++
+		}
+		if !errors.Is(err, ErrListTooLong) || count != 2 {
+			t.Errorf("Got %v after %d elements Wanted %v after 2", err, count, ErrListTooLong)
+		}
+	})
+
+	t.Run("Testing malformed lists", func(t *testing.T) {
+		var err error
+		var count int
+		for range RawValue("li1e4:spamd1:ai01eee").Elements(&err) {
+			count++
+		}
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("Expected *SyntaxError, got %v", err)
+		}
+		if count != 2 || syntaxErr.Path != "[2].a" {
+			t.Errorf("Got %d elements and path %q Wanted 2 elements and path \"[2].a\"", count, syntaxErr.Path)
+		}
+	})
+}
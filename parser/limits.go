@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors wrapped by the *SyntaxError returned when input exceeds a limit
+// configured in Options. Match them with errors.Is.
+var (
+	ErrMaxDepth           = errors.New("maximum nesting depth exceeded")
+	ErrStringTooLong      = errors.New("maximum string length exceeded")
+	ErrListTooLong        = errors.New("maximum list length exceeded")
+	ErrTooManyDictEntries = errors.New("maximum dictionary entries exceeded")
+	ErrAllocationLimit    = errors.New("maximum total allocation exceeded")
+)
+
+// DefaultMaxDepth is the nesting limit used when Options.MaxDepth is zero.
+// It keeps inputs such as "llllll..." from exhausting the goroutine stack.
+const DefaultMaxDepth = 10000
+
+// Approximate allocation charged per decoded list element and dictionary
+// entry on top of their string data, used for Options.MaxTotalAllocation
+const (
+	listElementCost = 16 // one interface value
+	dictEntryCost   = 48 // key string header, interface value and map overhead
+)
+
+// decodeState carries the options and running totals of a single parse
+type decodeState struct {
+	opts      *Options
+	depth     int
+	allocated int64
+
+	// cloneStrings is set when the input aliases a caller's []byte, so that
+	// returned strings don't share its memory
+	cloneStrings bool
+
+	// counts holds the pre-scanned size of each container in the order they
+	// open, and next is the index of the next one to be decoded
+	counts []int
+	next   int
+}
+
+// clone copies s when returned strings must not alias the input
+func (st *decodeState) clone(s string) string {
+	if st.cloneStrings {
+		return strings.Clone(s)
+	}
+	return s
+}
+
+func (o *Options) maxDepth() int {
+	if o.MaxDepth <= 0 {
+		return DefaultMaxDepth
+	}
+	return o.MaxDepth
+}
+
+// enter records the start of the list or dictionary at the beginning of s
+func (st *decodeState) enter(s string) error {
+	st.depth++
+	if st.depth > st.opts.maxDepth() {
+		return limitError(s, ErrMaxDepth, "nesting depth exceeds %d", st.opts.maxDepth())
+	}
+	return nil
+}
+
+// leave records the end of a list or dictionary
+func (st *decodeState) leave() {
+	st.depth--
+}
+
+// checkString validates a declared string length, where s starts at the string
+func (st *decodeState) checkString(s string, length int) error {
+	if st.opts.MaxStringLength > 0 && length > st.opts.MaxStringLength {
+		return limitError(s, ErrStringTooLong, "string length %d exceeds %d", length, st.opts.MaxStringLength)
+	}
+	return st.allocate(s, int64(length))
+}
+
+// addElement accounts for the n-th element of a list or entry of a
+// dictionary, where s starts at it. The first prepaid were charged when the
+// container was allocated.
+func (st *decodeState) addElement(s string, dict bool, n, prepaid int) error {
+	if dict {
+		if st.opts.MaxDictEntries > 0 && n > st.opts.MaxDictEntries {
+			return limitError(s, ErrTooManyDictEntries, "dictionary has more than %d entries", st.opts.MaxDictEntries)
+		}
+	} else if st.opts.MaxListLength > 0 && n > st.opts.MaxListLength {
+		return limitError(s, ErrListTooLong, "list has more than %d elements", st.opts.MaxListLength)
+	}
+	if n <= prepaid {
+		return nil
+	}
+	if dict {
+		return st.allocate(s, dictEntryCost)
+	}
+	return st.allocate(s, listElementCost)
+}
+
+func (st *decodeState) allocate(s string, n int64) error {
+	st.allocated += n
+	if st.opts.MaxTotalAllocation > 0 && st.allocated > st.opts.MaxTotalAllocation {
+		return limitError(s, ErrAllocationLimit, "decoded data exceeds %d bytes", st.opts.MaxTotalAllocation)
+	}
+	return nil
+}
+
+func limitError(s string, limit error, format string, args ...any) *SyntaxError {
+	e := syntaxError(s, 0, "", "limit exceeded: "+format, args...)
+	e.Err = limit
+	return e
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  Options
+		want  error
+	}{
+		{"depth", "lllleeee", Options{MaxDepth: 3}, ErrMaxDepth},
+		{"default depth", strings.Repeat("l", DefaultMaxDepth+1) + strings.Repeat("e", DefaultMaxDepth+1), Options{}, ErrMaxDepth},
+		{"string length", "9999999999:abc", Options{MaxStringLength: 1 << 20}, ErrStringTooLong},
+		{"list length", "li1ei2ei3ee", Options{MaxListLength: 2}, ErrListTooLong},
+		{"dictionary entries", "d1:ai1e1:bi2ee", Options{MaxDictEntries: 1}, ErrTooManyDictEntries},
+		{"total allocation", "l4:spam4:eggs4:spame", Options{MaxTotalAllocation: 40}, ErrAllocationLimit},
+	}
+
+	for _, test := range tests {
+		_, err := ParseWithOptions(test.input, test.opts)
+		if !errors.Is(err, test.want) {
+			t.Errorf("ParseWithOptions(%s) error = %v; want %v", test.name, err, test.want)
+		}
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("ParseWithOptions(%s): expected *SyntaxError, got %T", test.name, err)
+		}
+
+		var got any
+		if err := UnmarshalWithOptions([]byte(test.input), &got, test.opts); !errors.Is(err, test.want) {
+			t.Errorf("UnmarshalWithOptions(%s) error = %v; want %v", test.name, err, test.want)
+		}
+	}
+
+	t.Run("Testing input within limits", func(t *testing.T) {
+		opts := Options{MaxDepth: 2, MaxStringLength: 4, MaxListLength: 2, MaxDictEntries: 1, MaxTotalAllocation: 100}
+		if _, err := ParseWithOptions("d1:ali1e4:spamee", opts); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Testing unmarshal into structs honours limits", func(t *testing.T) {
+		var got struct {
+			List []int `bencode:"list"`
+		}
+		err := UnmarshalWithOptions([]byte("d4:listli1ei2ei3eee"), &got, Options{MaxListLength: 2})
+		if !errors.Is(err, ErrListTooLong) {
+			t.Errorf("Expected ErrListTooLong, got %v", err)
+		}
+	})
+}
+
+func TestDecoderLimits(t *testing.T) {
+	t.Run("Testing huge declared string is refused before buffering", func(t *testing.T) {
+		// The reader would supply the data, but the decoder must not wait for it
+		r := strings.NewReader("9999999999:" + strings.Repeat("x", 1<<16))
+		dec := NewDecoderWithOptions(r, Options{MaxStringLength: 1024})
+		if _, err := dec.Decode(); !errors.Is(err, ErrStringTooLong) {
+			t.Errorf("Expected ErrStringTooLong, got %v", err)
+		}
+		if r.Len() == 0 {
+			t.Error("Decoder read the entire string before rejecting it")
+		}
+	})
+
+	t.Run("Testing deep nesting from a stream", func(t *testing.T) {
+		dec := NewDecoderWithOptions(strings.NewReader(strings.Repeat("l", 1000)), Options{MaxDepth: 100})
+		if _, err := dec.Decode(); !errors.Is(err, ErrMaxDepth) {
+			t.Errorf("Expected ErrMaxDepth, got %v", err)
+		}
+	})
+
+	t.Run("Testing unbounded value is cut off", func(t *testing.T) {
+		r := strings.NewReader("i" + strings.Repeat("1", 1<<20))
+		dec := NewDecoderWithOptions(r, Options{MaxTotalAllocation: 4096})
+		if _, err := dec.Decode(); !errors.Is(err, ErrAllocationLimit) {
+			t.Errorf("Expected ErrAllocationLimit, got %v", err)
+		}
+		if r.Len() == 0 {
+			t.Error("Decoder buffered the whole value before rejecting it")
+		}
+	})
+}
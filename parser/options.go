@@ -0,0 +1,182 @@
+package parser
+
+import "io"
+
+// NumberMode selects how bencode integers are decoded
+type NumberMode int
+
+const (
+	// NumberInt64 decodes integers as int64 and rejects larger values with an
+	// error wrapping strconv.ErrRange
+	NumberInt64 NumberMode = iota
+
+	// NumberBigInt decodes integers as int64 when they fit and as *big.Int
+	// otherwise, since bencode places no bound on integer size
+	NumberBigInt
+)
+
+// Options configures optional parser behaviour. The zero value matches Parse.
+type Options struct {
+	// Strict rejects input that is well-formed but not canonical per BEP 3:
+	// unsorted or duplicate dictionary keys, string lengths and integers with
+	// a sign or leading zeros, and negative zero. Only canonical input has a
+	// single encoding, which keeps info-hashes unambiguous.
+	Strict bool
+
+	// RawKeys lists dictionary keys whose values, at any depth, are returned
+	// as a RawValue holding their original bytes instead of being decoded.
+	// Setting it to []string{"info"} exposes a torrent's info dictionary
+	// exactly as it appeared in the input.
+	RawKeys []string
+
+	// Numbers selects the Go type of decoded integers
+	Numbers NumberMode
+
+	// OrderedDicts decodes dictionaries as Dict, which keeps entries in
+	// input order, instead of map[string]any
+	OrderedDicts bool
+
+	// ListCapacity and DictCapacity set the capacity allocated up front for
+	// each decoded list and dictionary. Zero means DefaultListCapacity and
+	// DefaultDictCapacity. They are only hints: containers grow as needed,
+	// and no more is reserved than the remaining input could fill.
+	ListCapacity int
+	DictCapacity int
+
+	// PreScan makes Parse, ParseBytes and Decoder walk the input once before
+	// decoding it, counting the elements of every list and dictionary
+	// without decoding anything, so that each is allocated at its exact
+	// size. It overrides ListCapacity and DictCapacity and pays off for
+	// large inputs with big containers.
+	PreScan bool
+
+	// MaxDepth limits how deeply lists and dictionaries may nest. Zero
+	// means DefaultMaxDepth.
+	MaxDepth int
+
+	// MaxStringLength limits the declared length of any string, checked
+	// before the string data is read. Zero means no limit.
+	MaxStringLength int
+
+	// MaxListLength limits the number of elements in any list. Zero means
+	// no limit.
+	MaxListLength int
+
+	// MaxDictEntries limits the number of entries in any dictionary. Zero
+	// means no limit.
+	MaxDictEntries int
+
+	// MaxTotalAllocation limits the approximate number of bytes decoded for
+	// a single value, counting string data plus a fixed cost per list
+	// element and dictionary entry. A Decoder also refuses to buffer more
+	// than this many bytes of input for one value. Zero means no limit.
+	MaxTotalAllocation int64
+
+	// ZeroCopy makes the []byte entry points return strings, dictionary
+	// keys and raw values that share memory with the input instead of
+	// copies. The input must not be modified while they are in use. The
+	// string entry points always return substrings of their input.
+	ZeroCopy bool
+}
+
+// Capacities used when Options.ListCapacity and Options.DictCapacity are zero
+const (
+	DefaultListCapacity = 16
+	DefaultDictCapacity = 8
+)
+
+// defaultOptions is shared by the entry points that take no Options
+var defaultOptions Options
+
+// ParseWithOptions parses str like Parse using the given options
+func ParseWithOptions(str string, opts Options) (any, error) {
+	return parseAll(str, &decodeState{opts: &opts})
+}
+
+// ParseStrict parses str, rejecting any input that is not canonical bencode
+func ParseStrict(str string) (any, error) {
+	return ParseWithOptions(str, Options{Strict: true})
+}
+
+// NewDecoderWithOptions returns a decoder that reads from r using the given options
+func NewDecoderWithOptions(r io.Reader, opts Options) *Decoder {
+	return &Decoder{in: readBuffer{r: r}, opts: opts}
+}
+
+// UnmarshalWithOptions decodes data into v like Unmarshal using the given options
+func UnmarshalWithOptions(data []byte, v any, opts Options) error {
+	return unmarshal(data, v, &decodeState{opts: &opts, cloneStrings: !opts.ZeroCopy})
+}
+
+// NewTokenizerWithOptions returns a tokenizer over str like NewTokenizer
+// using the given options
+func NewTokenizerWithOptions(str string, opts Options) *Tokenizer {
+	return &Tokenizer{input: str, st: decodeState{opts: &opts}}
+}
+
+// NewTokenizerReaderWithOptions returns a tokenizer that reads from r like
+// NewTokenizerReader using the given options
+func NewTokenizerReaderWithOptions(r io.Reader, opts Options) *Tokenizer {
+	return &Tokenizer{in: &readBuffer{r: r}, st: decodeState{opts: &opts, cloneStrings: true}}
+}
+
+// NewDocumentWithOptions returns a document over str like NewDocument using
+// the given options
+func NewDocumentWithOptions(str string, opts Options) *Document {
+	return &Document{input: str, opts: opts, nodes: make(map[int]*docNode), ends: make(map[int]int)}
+}
+
+// listCapacity returns the capacity for a list whose encoding starts n bytes
+// before the end of the input. Every element takes at least two bytes.
+func (o *Options) listCapacity(n int) int {
+	c := o.ListCapacity
+	if c <= 0 {
+		c = DefaultListCapacity
+	}
+	return min(c, n/2)
+}
+
+// dictCapacity returns the capacity for a dictionary whose encoding starts n
+// bytes before the end of the input. Every entry takes at least four bytes.
+func (o *Options) dictCapacity(n int) int {
+	c := o.DictCapacity
+	if c <= 0 {
+		c = DefaultDictCapacity
+	}
+	return min(c, n/4)
+}
+
+// isCanonicalDigits reports whether s is a non-empty run of decimal digits
+// without leading zeros
+func isCanonicalDigits(s string) bool {
+	if len(s) == 0 || (len(s) > 1 && s[0] == '0') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isCanonicalInteger reports whether s is the canonical form of an integer:
+// an optional minus sign followed by canonical digits, excluding "-0"
+func isCanonicalInteger(s string) bool {
+	if len(s) > 0 && s[0] == '-' {
+		return s != "-0" && isCanonicalDigits(s[1:])
+	}
+	return isCanonicalDigits(s)
+}
+
+// checkKeyOrder rejects a dictionary key that does not sort strictly after
+// the previous one. s is the input starting at the key.
+func checkKeyOrder(s string, key, prevKey string) error {
+	if key == prevKey {
+		return syntaxError(s, 0, "unique key", "dictionary parsing error: duplicate key %s", quote(key))
+	}
+	if key < prevKey {
+		return syntaxError(s, 0, "sorted key", "dictionary parsing error: key %s is not sorted after %s", quote(key), quote(prevKey))
+	}
+	return nil
+}
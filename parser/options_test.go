@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseStrict(t *testing.T) {
+	t.Run("Testing canonical input is accepted", func(t *testing.T) {
+		str := "d1:ai-5e1:bli0e0:e2:bbd1:xi10eee"
+		got, err := ParseStrict(str)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want, _ := Parse(str)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Got %v Wanted %v", got, want)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		input  string
+		offset int64
+	}{
+		{"unsorted keys", "d1:bi1e1:ai2ee", 7},
+		{"duplicate keys", "d1:ai1e1:ai2ee", 7},
+		{"unsorted nested keys", "d1:ad1:yi1e1:xi2eee", 11},
+		{"leading zero length", "l01:ae", 1},
+		{"signed length", "+1:a", 0},
+		{"negative zero", "i-0e", 1},
+		{"signed integer", "i+5e", 1},
+	}
+
+	for _, test := range tests {
+		if _, err := Parse(test.input); err != nil {
+			t.Errorf("Parse(%s) should accept non-canonical input, got %v", test.name, err)
+		}
+
+		_, err := ParseStrict(test.input)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("ParseStrict(%s): expected *SyntaxError, got %v", test.name, err)
+			continue
+		}
+		if syntaxErr.Offset != test.offset {
+			t.Errorf("ParseStrict(%s): Got offset %d Wanted %d", test.name, syntaxErr.Offset, test.offset)
+		}
+	}
+
+	t.Run("Testing strict decoder and unmarshal", func(t *testing.T) {
+		dec := NewDecoderWithOptions(strings.NewReader("d1:bi1e1:ai2ee"), Options{Strict: true})
+		if _, err := dec.Decode(); err == nil {
+			t.Error("Expected error for unsorted keys from strict decoder, got nil")
+		}
+
+		var got map[string]int
+		if err := UnmarshalWithOptions([]byte("d1:bi1e1:ai2ee"), &got, Options{Strict: true}); err == nil {
+			t.Error("Expected error for unsorted keys from strict unmarshal, got nil")
+		}
+		if err := UnmarshalWithOptions([]byte("d1:ai1e1:bi2ee"), &got, Options{Strict: true}); err != nil {
+			t.Errorf("Unexpected error for sorted keys: %v", err)
+		}
+	})
+}
+
+func TestNumberMode(t *testing.T) {
+	huge := "340282366920938463463374607431768211456" // 2^128
+
+	t.Run("Testing oversized integer reports range error", func(t *testing.T) {
+		_, err := Parse("i" + huge + "e")
+		if !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("Expected error wrapping strconv.ErrRange, got %v", err)
+		}
+	})
+
+	t.Run("Testing NumberBigInt", func(t *testing.T) {
+		got, err := ParseWithOptions("li42ei"+huge+"ei-"+huge+"ee", Options{Numbers: NumberBigInt})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		list := got.([]any)
+		if list[0] != int64(42) {
+			t.Errorf("Got %#v Wanted int64(42) for a value that fits", list[0])
+		}
+		want, _ := new(big.Int).SetString(huge, 10)
+		if n, ok := list[1].(*big.Int); !ok || n.Cmp(want) != 0 {
+			t.Errorf("Got %v Wanted %v", list[1], want)
+		}
+		if n, ok := list[2].(*big.Int); !ok || n.Cmp(new(big.Int).Neg(want)) != 0 {
+			t.Errorf("Got %v Wanted -%v", list[2], want)
+		}
+	})
+
+	t.Run("Testing NumberBigInt keeps validation", func(t *testing.T) {
+		for _, input := range []string{"i0" + huge + "e", "i" + huge + "xe", "i-0e"} {
+			if _, err := ParseWithOptions(input, Options{Numbers: NumberBigInt, Strict: true}); err == nil {
+				t.Errorf("Expected error for %q, got nil", input)
+			}
+		}
+	})
+
+	t.Run("Testing unmarshal into big.Int", func(t *testing.T) {
+		var got struct {
+			Counter *big.Int `bencode:"counter"`
+			Small   big.Int  `bencode:"small"`
+			Plain   int64    `bencode:"plain"`
+		}
+		data := "d7:counteri" + huge + "e5:plaini1e5:smalli7ee"
+		if err := Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got.Counter.String() != huge || got.Small.Int64() != 7 || got.Plain != 1 {
+			t.Errorf("Got %v %v %v", got.Counter, &got.Small, got.Plain)
+		}
+
+		var typeErr *UnmarshalTypeError
+		err := UnmarshalWithOptions([]byte("d5:plaini"+huge+"ee"), &got, Options{Numbers: NumberBigInt})
+		if !errors.As(err, &typeErr) {
+			t.Errorf("Expected *UnmarshalTypeError for oversized int64 field, got %v", err)
+		}
+	})
+}
+
+func TestCapacities(t *testing.T) {
+	large := "l" + strings.Repeat("d1:ai1e1:bl1:xee", 200) + "e"
+
+	t.Run("Testing capacities do not change results", func(t *testing.T) {
+		for _, input := range []string{"li1ei2ee", "d1:ai1ee", large} {
+			want, _ := Parse(input)
+			for _, opts := range []Options{{ListCapacity: 1, DictCapacity: 1}, {ListCapacity: 1000, DictCapacity: 1000}} {
+				got, err := ParseWithOptions(input, opts)
+				if err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("ParseWithOptions(%.20q, %+v) = %v, %v; want %v", input, opts, got, err, want)
+				}
+			}
+			got, err := ParseWithEstimation(input)
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseWithEstimation(%.20q) = %v, %v; want %v", input, got, err, want)
+			}
+		}
+	})
+
+	t.Run("Testing list capacity is applied", func(t *testing.T) {
+		got, err := ParseWithOptions(large, Options{ListCapacity: 300})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if c := cap(got.([]any)); c != 300 {
+			t.Errorf("Got capacity %d Wanted %d", c, 300)
+		}
+	})
+
+	t.Run("Testing capacity is bounded by the input", func(t *testing.T) {
+		got, err := ParseWithOptions("li1ee", Options{ListCapacity: 1 << 40})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if c := cap(got.([]any)); c > 5 {
+			t.Errorf("Got capacity %d for a 5 byte input", c)
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package parser
 
 import (
-	"fmt"
+	"errors"
+	"math/big"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -9,182 +11,382 @@ import (
 // ParseInteger parses bencode integers with reduced string operations
 func ParseInteger(str string) (int, error) {
 	if len(str) < 3 || str[0] != 'i' || str[len(str)-1] != 'e' {
-		return 0, fmt.Errorf("integer parsing error: invalid format %q", str)
+		return 0, locateError(syntaxError(str, 0, "integer", "integer parsing error: invalid format %s", quote(str)), str, 0)
 	}
 
+	res, err := parseIntegerDigits(str, len(str)-1)
+	if err != nil {
+		return 0, locateError(err, str, 0)
+	}
+	if int64(int(res)) != res {
+		return 0, locateError(rangeError(str, res), str, 0)
+	}
+
+	return int(res), nil
+}
+
+// parseIntegerDigits validates and converts s[1:eIndex], the digits of an
+// integer token at the start of s
+func parseIntegerDigits(s string, eIndex int) (int64, error) {
 	// Parse directly without string trimming operations
-	numStr := str[1 : len(str)-1] // Remove 'i' and 'e' without allocations
+	numStr := s[1:eIndex] // Remove 'i' and 'e' without allocations
 
-	// Check for leading zeros before calling strconv.Atoi
+	// Check for leading zeros before calling strconv.ParseInt
 	if (len(numStr) > 1 && numStr[0] == '0') || (len(numStr) > 2 && numStr[0] == '-' && numStr[1] == '0') {
-		return 0, fmt.Errorf("integer parsing error: malformed integer with leading zero %q", numStr)
+		return 0, syntaxError(s, 1, "digit", "integer parsing error: malformed integer with leading zero %s", quote(numStr))
 	}
 
-	res, err := strconv.Atoi(numStr)
+	res, err := strconv.ParseInt(numStr, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		e := syntaxError(s, 1, "", "integer parsing error: value %s does not fit in 64 bits", quote(numStr))
+		e.Err = strconv.ErrRange
+		return 0, e
+	}
 	if err != nil {
-		return 0, fmt.Errorf("integer parsing error: invalid format or value %q", numStr)
+		return 0, syntaxError(s, 1, "digit", "integer parsing error: invalid format or value %s", quote(numStr))
 	}
 
 	return res, nil
 }
 
+// parseBigIntegerDigits is parseIntegerDigits for NumberBigInt. Values that
+// fit are still returned as int64, larger ones as *big.Int.
+func parseBigIntegerDigits(s string, eIndex int) (any, error) {
+	res, err := parseIntegerDigits(s, eIndex)
+	if err == nil {
+		return res, nil
+	}
+	if !errors.Is(err, strconv.ErrRange) {
+		return nil, err
+	}
+
+	// ParseInt only reports ErrRange for well-formed digits, which SetString accepts
+	n, _ := new(big.Int).SetString(s[1:eIndex], 10)
+	return n, nil
+}
+
+// rangeError reports an integer that is valid bencode but too large for int
+func rangeError(s string, n int64) *SyntaxError {
+	e := syntaxError(s, 1, "", "integer parsing error: value %d does not fit in int", n)
+	e.Err = strconv.ErrRange
+	return e
+}
+
 // ParseString parses bencode strings with optimized operations
 func ParseString(str string) (string, error) {
 	colonIndex := strings.IndexByte(str, ':') // Use IndexByte instead of Index
 	if colonIndex == -1 {
-		return "", fmt.Errorf("string parsing error: missing colon in %q", str)
+		return "", locateError(syntaxError(str, 0, "':'", "string parsing error: missing colon"), str, 0)
 	}
 
 	lengthStr := str[:colonIndex]
 	length, err := strconv.Atoi(lengthStr)
 	if err != nil {
-		return "", fmt.Errorf("string parsing error: invalid length %q in %q", lengthStr, str)
+		return "", locateError(syntaxError(str, 0, "string length", "string parsing error: invalid length %s", quote(lengthStr)), str, 0)
 	}
 	if length < 0 {
-		return "", fmt.Errorf("string parsing error: negative length %d in %q", length, str)
+		return "", locateError(syntaxError(str, 0, "string length", "string parsing error: negative length %d", length), str, 0)
 	}
 
 	// Check for leading zeros in length (e.g., "05:hello" is invalid)
 	if len(lengthStr) > 1 && lengthStr[0] == '0' {
-		return "", fmt.Errorf("string parsing error: malformed length with leading zero %q in %q", lengthStr, str)
+		return "", locateError(syntaxError(str, 0, "string length", "string parsing error: malformed length with leading zero %s", quote(lengthStr)), str, 0)
 	}
 
 	stringValueStartIndex := colonIndex + 1
-	bencodedStringFullLength := stringValueStartIndex + length
-
-	if len(str) < bencodedStringFullLength {
-		return "", fmt.Errorf("string parsing error: declared length %d exceeds actual string length in %q", length, str)
+	if length > len(str)-stringValueStartIndex {
+		return "", locateError(syntaxError(str, stringValueStartIndex, "string data", "string parsing error: declared length %d exceeds remaining %d bytes", length, len(str)-stringValueStartIndex), str, 0)
 	}
 
-	stringValue := str[stringValueStartIndex : stringValueStartIndex+length]
+	bencodedStringFullLength := stringValueStartIndex + length
+	stringValue := str[stringValueStartIndex:bencodedStringFullLength]
 
 	if len(str) > bencodedStringFullLength {
-		return "", fmt.Errorf("string parsing error: extra data %q after declared string length in %q", str[bencodedStringFullLength:], str)
+		return "", locateError(syntaxError(str, bencodedStringFullLength, "end of input", "string parsing error: extra data after declared string length"), str, 0)
 	}
 
 	return stringValue, nil
 }
 
-// parseBencodedValue is the core optimized parsing function with pre-allocation
+// parseBencodedValue parses the value at the start of s with default options
 func parseBencodedValue(s string) (any, string, error) {
+	return parseValue(s, &decodeState{opts: &defaultOptions})
+}
+
+// parseValue is the core optimized parsing function with pre-allocation
+func parseValue(s string, st *decodeState) (any, string, error) {
 	if len(s) == 0 {
-		return nil, "", fmt.Errorf("empty string for parsing Bencode value")
+		return nil, "", syntaxError(s, 0, "value", "empty string for parsing Bencode value")
 	}
 
 	switch s[0] {
 	case 'i':
-		eIndex := strings.IndexByte(s, 'e')
-		if eIndex == -1 {
-			return nil, "", fmt.Errorf("integer parsing error: missing 'e' in %q", s)
+		eIndex, err := scanInteger(s, st)
+		if err != nil {
+			return nil, "", err
 		}
-		// Basic check for malformed 'i' (like "ie" or "i-e" without digits)
-		if eIndex == 1 && (s[1] == 'e' || s[1] == '-') {
-			return nil, "", fmt.Errorf("integer parsing error: malformed integer %q", s[:eIndex+1])
+		if st.opts.Numbers == NumberBigInt {
+			val, err := parseBigIntegerDigits(s, eIndex)
+			if err != nil {
+				return nil, "", err
+			}
+			return val, s[eIndex+1:], nil
 		}
-		intStr := s[:eIndex+1]
-		val, err := ParseInteger(intStr)
+		val, err := parseIntegerDigits(s, eIndex)
 		if err != nil {
 			return nil, "", err
 		}
-		return int64(val), s[eIndex+1:], nil // Convert int to int64 for consistency with bencode specs
+		return val, s[eIndex+1:], nil // Integers are int64 for consistency with bencode specs
 
 	case 'l':
-		current := s[1:] // Skip 'l'
 		// Pre-allocate slice with reasonable capacity to reduce reallocations
-		list := make([]any, 0, 16)
-
-		for len(current) > 0 && current[0] != 'e' {
-			val, remaining, err := parseBencodedValue(current)
+		capacity, prepaid := st.listCapacity(s)
+		list := make([]any, 0, capacity)
+		remaining, err := walkContainer(s, st, prepaid, func(current, _ string, _ int) (string, error) {
+			val, remaining, err := parseValue(current, st)
 			if err != nil {
-				return nil, "", err
+				return "", err
 			}
 			list = append(list, val)
-			current = remaining
-		}
-
-		if len(current) == 0 || current[0] != 'e' {
-			return nil, "", fmt.Errorf("list parsing error: missing 'e' at end of list elements in %q", s)
+			return remaining, nil
+		})
+		if err != nil {
+			return nil, "", err
 		}
-
-		return list, current[1:], nil // Return the list and string after 'e'
+		return list, remaining, nil
 
 	case 'd':
-		current := s[1:] // Skip 'd'
 		// Pre-allocate map with reasonable capacity to reduce hash table resizing
-		dict := make(map[string]any, 8)
-
-		for len(current) > 0 && current[0] != 'e' {
-			// Parse key (must be a string)
-			key, remaining, err := parseBencodedValue(current)
+		var dict map[string]any
+		var ordered Dict
+		capacity, prepaid := st.dictCapacity(s)
+		if st.opts.OrderedDicts {
+			ordered = make(Dict, 0, capacity)
+		} else {
+			dict = make(map[string]any, capacity)
+		}
+		remaining, err := walkContainer(s, st, prepaid, func(current, key string, _ int) (string, error) {
+			value, remaining, err := parseValue(current, st)
 			if err != nil {
-				return nil, "", err
+				return "", err
 			}
-			keyStr, ok := key.(string)
-			if !ok {
-				return nil, "", fmt.Errorf("dictionary key must be a string, got %T in %q", key, s)
+			if len(st.opts.RawKeys) > 0 && slices.Contains(st.opts.RawKeys, key) {
+				value = RawValue(st.clone(current[:len(current)-len(remaining)]))
 			}
-			current = remaining
-
-			// Check if we have a value
-			if len(current) == 0 {
-				return nil, "", fmt.Errorf("dictionary missing value for key %q in %q", keyStr, s)
+			key = st.clone(key)
+			if ordered != nil {
+				ordered = append(ordered, DictEntry{Key: key, Value: value})
+			} else {
+				dict[key] = value
 			}
+			return remaining, nil
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		if ordered != nil {
+			return ordered, remaining, nil
+		}
+		return dict, remaining, nil
+
+	default: // Must be a string (starts with a digit)
+		val, remaining, err := scanString(s, st)
+		if err != nil {
+			return nil, "", err
+		}
+		return st.clone(val), remaining, nil
+	}
+}
+
+// scanInteger validates the integer at the start of s and returns the index
+// of its terminating 'e'
+func scanInteger(s string, st *decodeState) (int, error) {
+	eIndex := strings.IndexByte(s, 'e')
+	if eIndex == -1 {
+		return 0, syntaxError(s, 0, "'e'", "integer parsing error: missing 'e'")
+	}
+	// Basic check for malformed 'i' (like "ie" or "i-e" without digits)
+	if eIndex == 1 && (s[1] == 'e' || s[1] == '-') {
+		return 0, syntaxError(s, 1, "digit", "integer parsing error: malformed integer %s", quote(s[:eIndex+1]))
+	}
+	if st.opts.Strict && !isCanonicalInteger(s[1:eIndex]) {
+		return 0, syntaxError(s, 1, "digit", "integer parsing error: non-canonical integer %s", quote(s[1:eIndex]))
+	}
+	return eIndex, nil
+}
+
+// maxLengthPrefix bounds a string length prefix to a sign and the digits of
+// the largest int, so that streaming readers never buffer an endless one
+const maxLengthPrefix = len("9223372036854775807") + 1
+
+// scanString validates the string at the start of s and returns its data
+// and the input following it
+func scanString(s string, st *decodeState) (string, string, error) {
+	colonIndex := strings.IndexByte(s, ':')
+	if colonIndex == -1 {
+		return "", "", syntaxError(s, 0, "':'", "string parsing error: missing colon")
+	}
+	lengthStr := s[:colonIndex]
+	if len(lengthStr) > maxLengthPrefix {
+		return "", "", syntaxError(s, 0, "string length", "string parsing error: invalid length %s", quote(lengthStr))
+	}
+	if st.opts.Strict && !isCanonicalDigits(lengthStr) {
+		return "", "", syntaxError(s, 0, "string length", "string parsing error: non-canonical length %s", quote(lengthStr))
+	}
+	length, err := strconv.Atoi(lengthStr)
+	if err != nil {
+		return "", "", syntaxError(s, 0, "string length", "string parsing error: invalid length %s", quote(lengthStr))
+	}
+	if length < 0 {
+		return "", "", syntaxError(s, 0, "string length", "string parsing error: negative length %d", length)
+	}
+	if err := st.checkString(s, length); err != nil {
+		return "", "", err
+	}
+
+	stringValueStartIndex := colonIndex + 1
+	// Compare against the remaining input so a huge length cannot overflow
+	if length > len(s)-stringValueStartIndex {
+		return "", "", syntaxError(s, stringValueStartIndex, "string data", "string parsing error: declared length %d exceeds remaining %d bytes", length, len(s)-stringValueStartIndex)
+	}
+
+	stringValueEndIndex := stringValueStartIndex + length
+	return s[stringValueStartIndex:stringValueEndIndex], s[stringValueEndIndex:], nil
+}
+
+// scanKey validates the dictionary key at the start of s and returns it and
+// the input following it
+func scanKey(s string, st *decodeState) (string, string, error) {
+	if s[0] == 'i' || s[0] == 'l' || s[0] == 'd' {
+		return "", "", syntaxError(s, 0, "string key", "dictionary key must be a string, got %s", kindOf(s))
+	}
+	return scanString(s, st)
+}
+
+// scanEntryKey is scanKey for the n-th key of a dictionary, counting from
+// zero, which with Options.Strict must sort after the previous key prevKey
+func scanEntryKey(s string, st *decodeState, n int, prevKey string) (string, string, error) {
+	key, remaining, err := scanKey(s, st)
+	if err != nil {
+		return "", "", err
+	}
+	if st.opts.Strict && n > 0 {
+		if err := checkKeyOrder(s, key, prevKey); err != nil {
+			return "", "", err
+		}
+	}
+	return key, remaining, nil
+}
 
-			// Parse value
-			value, remaining, err := parseBencodedValue(current)
+// walkContainer walks the list or dictionary at the start of s and returns
+// the input following it. It checks the nesting and size limits, the keys
+// and the closing 'e', and leaves each element to value, which is called
+// with the input starting at the element, its key (empty in a list) and its
+// index, and returns the input following the element. Errors from value are
+// prefixed with the element's path. The first prepaid elements were charged
+// for when the container was allocated.
+func walkContainer(s string, st *decodeState, prepaid int, value func(current, key string, n int) (string, error)) (string, error) {
+	if err := st.enter(s); err != nil {
+		return "", err
+	}
+	defer st.leave()
+
+	dict := s[0] == 'd'
+	current := s[1:]
+	var key string
+	for n := 0; len(current) > 0 && current[0] != 'e'; n++ {
+		if err := st.addElement(current, dict, n+1, prepaid); err != nil {
+			return "", err
+		}
+		if dict {
+			k, remaining, err := scanEntryKey(current, st, n, key)
 			if err != nil {
-				return nil, "", err
+				return "", err
+			}
+			key, current = k, remaining
+			if len(current) == 0 {
+				return "", syntaxError(current, 0, "value", "dictionary missing value for key %s", quote(key))
 			}
-			dict[keyStr] = value
-			current = remaining
 		}
 
-		if len(current) == 0 || current[0] != 'e' {
-			return nil, "", fmt.Errorf("dictionary parsing error: missing 'e' at end of dictionary in %q", s)
+		remaining, err := value(current, key, n)
+		if err != nil {
+			if dict {
+				return "", prefixPath(err, st.clone(key))
+			}
+			return "", prefixPath(err, "["+strconv.Itoa(n)+"]")
 		}
+		current = remaining
+	}
 
-		return dict, current[1:], nil // Return the dict and string after 'e'
+	if len(current) == 0 {
+		return "", missingEnd(current, dict)
+	}
+	return current[1:], nil
+}
 
-	default: // Must be a string (starts with a digit)
-		colonIndex := strings.IndexByte(s, ':')
-		if colonIndex == -1 {
-			return nil, "", fmt.Errorf("string parsing error: missing colon in %q", s)
-		}
-		lengthStr := s[:colonIndex]
-		length, err := strconv.Atoi(lengthStr)
+// missingEnd reports a list or dictionary that ends before its closing 'e'
+func missingEnd(s string, dict bool) *SyntaxError {
+	if dict {
+		return syntaxError(s, 0, "'e'", "dictionary parsing error: missing 'e' at end of dictionary")
+	}
+	return syntaxError(s, 0, "'e'", "list parsing error: missing 'e' at end of list elements")
+}
+
+// skipValue validates the value at the start of s without decoding it and
+// returns the input following it
+func skipValue(s string, st *decodeState) (string, error) {
+	if len(s) == 0 {
+		return "", syntaxError(s, 0, "value", "empty string for parsing Bencode value")
+	}
+
+	switch s[0] {
+	case 'i':
+		eIndex, err := scanInteger(s, st)
 		if err != nil {
-			return nil, "", fmt.Errorf("string parsing error: invalid length %q in %q", lengthStr, s)
+			return "", err
 		}
-		if length < 0 {
-			return nil, "", fmt.Errorf("string parsing error: negative length %d in %q", length, s)
+		if st.opts.Numbers == NumberBigInt {
+			_, err = parseBigIntegerDigits(s, eIndex)
+		} else {
+			_, err = parseIntegerDigits(s, eIndex)
 		}
-
-		stringValueStartIndex := colonIndex + 1
-		stringValueEndIndex := stringValueStartIndex + length
-
-		if stringValueEndIndex > len(s) {
-			return nil, "", fmt.Errorf("string parsing error: declared length %d (%q) exceeds actual string length in %q", length, lengthStr, s)
+		if err != nil {
+			return "", err
 		}
+		return s[eIndex+1:], nil
 
-		val := s[stringValueStartIndex:stringValueEndIndex]
-		return val, s[stringValueEndIndex:], nil
+	case 'l', 'd':
+		return walkContainer(s, st, 0, func(current, _ string, _ int) (string, error) {
+			return skipValue(current, st)
+		})
+
+	default:
+		_, remaining, err := scanString(s, st)
+		return remaining, err
 	}
 }
 
 // ParseList parses bencode lists with pre-allocation optimizations
 func ParseList(str string) ([]any, error) {
-	val, remaining, err := parseBencodedValue(str)
+	return parseList(str, &decodeState{opts: &defaultOptions})
+}
+
+// parseList parses str as a single list, shared by ParseList and ParseListBytes
+func parseList(str string, st *decodeState) ([]any, error) {
+	st.prescan(str)
+	val, remaining, err := parseValue(str, st)
 	if err != nil {
-		return nil, err
+		return nil, locateError(err, str, 0)
 	}
 
 	listVal, ok := val.([]any)
 	if !ok {
-		return nil, fmt.Errorf("input %q was not a bencoded list, parsed as %T", str, val)
+		return nil, locateError(syntaxError(str, 0, "list", "input was not a bencoded list, parsed as %T", val), str, 0)
 	}
 
 	if len(remaining) > 0 {
-		return nil, fmt.Errorf("extra data %q after list %q", remaining, str)
+		return nil, locateError(syntaxError(remaining, 0, "end of input", "extra data after list"), str, 0)
 	}
 
 	return listVal, nil
@@ -192,18 +394,25 @@ func ParseList(str string) ([]any, error) {
 
 // ParseDictionary parses bencode dictionaries with pre-allocation optimizations
 func ParseDictionary(str string) (map[string]any, error) {
-	val, remaining, err := parseBencodedValue(str)
+	return parseDictionary(str, &decodeState{opts: &defaultOptions})
+}
+
+// parseDictionary parses str as a single dictionary, shared by
+// ParseDictionary and ParseDictionaryBytes
+func parseDictionary(str string, st *decodeState) (map[string]any, error) {
+	st.prescan(str)
+	val, remaining, err := parseValue(str, st)
 	if err != nil {
-		return nil, err
+		return nil, locateError(err, str, 0)
 	}
 
 	dictVal, ok := val.(map[string]any)
 	if !ok {
-		return nil, fmt.Errorf("input %q was not a bencoded dictionary, parsed as %T", str, val)
+		return nil, locateError(syntaxError(str, 0, "dictionary", "input was not a bencoded dictionary, parsed as %T", val), str, 0)
 	}
 
 	if len(remaining) > 0 {
-		return nil, fmt.Errorf("extra data %q after dictionary %q", remaining, str)
+		return nil, locateError(syntaxError(remaining, 0, "end of input", "extra data after dictionary"), str, 0)
 	}
 
 	return dictVal, nil
@@ -211,19 +420,29 @@ func ParseDictionary(str string) (map[string]any, error) {
 
 // Parse is the main optimized parsing function
 func Parse(str string) (any, error) {
-	val, remaining, err := parseBencodedValue(str)
+	return ParseWithOptions(str, Options{})
+}
+
+// parseAll parses str as a single value, shared by the Parse variants
+func parseAll(str string, st *decodeState) (any, error) {
+	st.prescan(str)
+	val, remaining, err := parseValue(str, st)
 	if err != nil {
-		return nil, err
+		return nil, locateError(err, str, 0)
 	}
 
 	if len(remaining) > 0 {
-		return nil, fmt.Errorf("extra data %q after value %q", remaining, str)
+		return nil, locateError(syntaxError(remaining, 0, "end of input", "extra data after value"), str, 0)
 	}
 
 	return val, nil
 }
 
-// EstimateCapacity provides heuristic-based capacity estimation for better pre-allocation
+// EstimateCapacity provides heuristic-based capacity estimation for better pre-allocation.
+//
+// Deprecated: the heuristic counts bytes inside string payloads, so its
+// estimates are unreliable for binary data. Use Options.PreScan, which finds
+// the exact size of every container.
 func EstimateCapacity(s string) (listCap, dictCap int) {
 	// Simple heuristics based on string analysis
 	listMarkers := strings.Count(s, "l")
@@ -257,132 +476,9 @@ func max(a, b int) int {
 	return b
 }
 
-// ParseWithEstimation uses capacity estimation for even better performance
+// ParseWithEstimation parses str with every list and dictionary allocated
+// at its exact size, found by a structural pre-scan. It is equivalent to
+// calling ParseWithOptions with PreScan set.
 func ParseWithEstimation(str string) (any, error) {
-	// For very large inputs, use estimation
-	if len(str) > 1000 {
-		listCap, dictCap := EstimateCapacity(str)
-		return parseWithCapacities(str, listCap, dictCap)
-	}
-
-	// For smaller inputs, use standard optimized parsing
-	return Parse(str)
-}
-
-// parseWithCapacities uses estimated capacities for pre-allocation
-func parseWithCapacities(s string, listCap, dictCap int) (any, error) {
-	val, remaining, err := parseBencodedValueWithCapacities(s, listCap, dictCap)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(remaining) > 0 {
-		return nil, fmt.Errorf("extra data %q after value %q", remaining, s)
-	}
-
-	return val, nil
-}
-
-// parseBencodedValueWithCapacities uses custom capacities for pre-allocation
-func parseBencodedValueWithCapacities(s string, listCap, dictCap int) (any, string, error) {
-	if len(s) == 0 {
-		return nil, "", fmt.Errorf("empty string for parsing Bencode value")
-	}
-
-	switch s[0] {
-	case 'i':
-		eIndex := strings.IndexByte(s, 'e')
-		if eIndex == -1 {
-			return nil, "", fmt.Errorf("integer parsing error: missing 'e' in %q", s)
-		}
-		if eIndex == 1 && (s[1] == 'e' || s[1] == '-') {
-			return nil, "", fmt.Errorf("integer parsing error: malformed integer %q", s[:eIndex+1])
-		}
-		intStr := s[:eIndex+1]
-		val, err := ParseInteger(intStr)
-		if err != nil {
-			return nil, "", err
-		}
-		return int64(val), s[eIndex+1:], nil
-
-	case 'l':
-		current := s[1:] // Skip 'l'
-		// Use estimated capacity
-		list := make([]any, 0, listCap)
-
-		for len(current) > 0 && current[0] != 'e' {
-			val, remaining, err := parseBencodedValueWithCapacities(current, listCap, dictCap)
-			if err != nil {
-				return nil, "", err
-			}
-			list = append(list, val)
-			current = remaining
-		}
-
-		if len(current) == 0 || current[0] != 'e' {
-			return nil, "", fmt.Errorf("list parsing error: missing 'e' at end of list elements in %q", s)
-		}
-
-		return list, current[1:], nil
-
-	case 'd':
-		current := s[1:] // Skip 'd'
-		// Use estimated capacity
-		dict := make(map[string]any, dictCap)
-
-		for len(current) > 0 && current[0] != 'e' {
-			// Parse key (must be a string)
-			key, remaining, err := parseBencodedValueWithCapacities(current, listCap, dictCap)
-			if err != nil {
-				return nil, "", err
-			}
-			keyStr, ok := key.(string)
-			if !ok {
-				return nil, "", fmt.Errorf("dictionary key must be a string, got %T in %q", key, s)
-			}
-			current = remaining
-
-			if len(current) == 0 {
-				return nil, "", fmt.Errorf("dictionary missing value for key %q in %q", keyStr, s)
-			}
-
-			// Parse value
-			value, remaining, err := parseBencodedValueWithCapacities(current, listCap, dictCap)
-			if err != nil {
-				return nil, "", err
-			}
-			dict[keyStr] = value
-			current = remaining
-		}
-
-		if len(current) == 0 || current[0] != 'e' {
-			return nil, "", fmt.Errorf("dictionary parsing error: missing 'e' at end of dictionary in %q", s)
-		}
-
-		return dict, current[1:], nil
-
-	default: // String parsing
-		colonIndex := strings.IndexByte(s, ':')
-		if colonIndex == -1 {
-			return nil, "", fmt.Errorf("string parsing error: missing colon in %q", s)
-		}
-		lengthStr := s[:colonIndex]
-		length, err := strconv.Atoi(lengthStr)
-		if err != nil {
-			return nil, "", fmt.Errorf("string parsing error: invalid length %q in %q", lengthStr, s)
-		}
-		if length < 0 {
-			return nil, "", fmt.Errorf("string parsing error: negative length %d in %q", length, s)
-		}
-
-		stringValueStartIndex := colonIndex + 1
-		stringValueEndIndex := stringValueStartIndex + length
-
-		if stringValueEndIndex > len(s) {
-			return nil, "", fmt.Errorf("string parsing error: declared length %d (%q) exceeds actual string length in %q", length, lengthStr, s)
-		}
-
-		val := s[stringValueStartIndex:stringValueEndIndex]
-		return val, s[stringValueEndIndex:], nil
-	}
+	return ParseWithOptions(str, Options{PreScan: true})
 }
@@ -36,7 +36,7 @@ func TestParseInteger(t *testing.T) {
 			t.Error("Error expected. Got nil")
 		}
 
-		errMsg := "integer parsing error: invalid format or value \"abc\""
+		errMsg := "integer parsing error: invalid format or value \"abc\" at offset 1"
 
 		if err.Error() != errMsg {
 			t.Errorf("Unexpected Error message. Wanted %q, got %q", errMsg, err.Error())
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// scanCounts returns the number of elements of every list and entries of
+// every dictionary in the value at the start of s, in the order the
+// containers open. It decodes nothing and skips string payloads by their
+// declared length, so binary data such as pieces costs nothing to pass over.
+// It returns false for malformed input and leaves reporting it to the parser.
+func scanCounts(s string) ([]int, bool) {
+	type open struct {
+		index int // of the container in counts
+		dict  bool
+	}
+	var counts []int
+	var stack []open
+
+	for i := 0; i < len(s); {
+		if s[i] == 'e' {
+			if len(stack) == 0 {
+				return nil, false
+			}
+			top := stack[len(stack)-1]
+			if top.dict {
+				if counts[top.index]%2 != 0 {
+					return nil, false // key without a value
+				}
+				counts[top.index] /= 2 // keys and values were both counted
+			}
+			stack = stack[:len(stack)-1]
+			i++
+		} else {
+			if len(stack) > 0 {
+				counts[stack[len(stack)-1].index]++
+			}
+
+			switch s[i] {
+			case 'i':
+				eIndex := strings.IndexByte(s[i:], 'e')
+				if eIndex == -1 {
+					return nil, false
+				}
+				i += eIndex + 1
+			case 'l', 'd':
+				stack = append(stack, open{index: len(counts), dict: s[i] == 'd'})
+				counts = append(counts, 0)
+				i++
+			default:
+				colonIndex := strings.IndexByte(s[i:], ':')
+				if colonIndex == -1 {
+					return nil, false
+				}
+				length, err := strconv.Atoi(s[i : i+colonIndex])
+				start := i + colonIndex + 1
+				if err != nil || length < 0 || length > len(s)-start {
+					return nil, false
+				}
+				i = start + length
+			}
+		}
+
+		if len(stack) == 0 {
+			return counts, true
+		}
+	}
+	return nil, false
+}
+
+// prescan fills in the exact container sizes of s when Options.PreScan is set
+func (st *decodeState) prescan(s string) {
+	if st.opts.PreScan {
+		st.counts, _ = scanCounts(s)
+	}
+}
+
+// listCapacity returns the capacity for the list at the start of s and how
+// many of its elements were charged for up front
+func (st *decodeState) listCapacity(s string) (int, int) {
+	if c, ok := st.nextCount(); ok {
+		c = st.reserve(c, st.opts.MaxListLength, listElementCost)
+		return c, c
+	}
+	return st.opts.listCapacity(len(s)), 0
+}
+
+// dictCapacity returns the capacity for the dictionary at the start of s and
+// how many of its entries were charged for up front
+func (st *decodeState) dictCapacity(s string) (int, int) {
+	if c, ok := st.nextCount(); ok {
+		c = st.reserve(c, st.opts.MaxDictEntries, dictEntryCost)
+		return c, c
+	}
+	return st.opts.dictCapacity(len(s)), 0
+}
+
+// reserve clamps the pre-scanned size c of a container to its limit and to
+// the allocation budget left at cost per element, and charges for it, so
+// that input beyond the limits fails as it fills the container rather than
+// being allocated for in full first
+func (st *decodeState) reserve(c, limit int, cost int64) int {
+	if limit > 0 {
+		c = min(c, limit)
+	}
+	if budget := st.opts.MaxTotalAllocation; budget > 0 {
+		c = int(min(int64(c), (budget-st.allocated)/cost))
+	}
+	st.allocated += int64(c) * cost
+	return c
+}
+
+// nextCount returns the pre-scanned size of the next container to be decoded
+func (st *decodeState) nextCount() (int, bool) {
+	if st.next >= len(st.counts) {
+		return 0, false
+	}
+	c := st.counts[st.next]
+	st.next++
+	return c, true
+}
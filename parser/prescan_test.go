@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPreScan(t *testing.T) {
+	t.Run("Testing container counts", func(t *testing.T) {
+		tests := []struct {
+			input    string
+			expected []int
+		}{
+			{"i42e", nil},
+			{"le", []int{0}},
+			{"l4:spami1eli2eedee", []int{4, 1, 0}},
+			{"d1:ad1:b3:i:le1:cl1:eee", []int{2, 1, 1}},
+			{"d6:pieces6:lldd::e", []int{1}},
+			{"li1eei2e", []int{1}},
+		}
+
+		for _, test := range tests {
+			got, ok := scanCounts(test.input)
+			if !ok || !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("scanCounts(%q) = %v, %v; want %v", test.input, got, ok, test.expected)
+			}
+		}
+	})
+
+	t.Run("Testing malformed input", func(t *testing.T) {
+		for _, input := range []string{"", "e", "l", "li1", "d1:ae", "5:abc", "l9223372036854775807:xe"} {
+			if got, ok := scanCounts(input); ok {
+				t.Errorf("scanCounts(%q) = %v; want failure", input, got)
+			}
+		}
+	})
+
+	t.Run("Testing exact capacities", func(t *testing.T) {
+		input := "d5:filesl" + strings.Repeat("d6:lengthi1ee", 100) + "e4:name4:teste"
+		got, err := ParseWithOptions(input, Options{PreScan: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want, _ := Parse(input)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Got %v Wanted %v", got, want)
+		}
+
+		files := got.(map[string]any)["files"].([]any)
+		if cap(files) != 100 {
+			t.Errorf("Got capacity %d Wanted %d", cap(files), 100)
+		}
+	})
+
+	t.Run("Testing counts are clamped to limits", func(t *testing.T) {
+		input := "l" + strings.Repeat("i1e", 1000000) + "e"
+		tests := []struct {
+			opts     Options
+			capacity int
+			err      error
+		}{
+			{Options{PreScan: true, MaxListLength: 10}, 10, ErrListTooLong},
+			{Options{PreScan: true, MaxTotalAllocation: 1000}, 1000 / listElementCost, ErrAllocationLimit},
+			{Options{PreScan: true}, 1000000, nil},
+		}
+
+		for _, test := range tests {
+			st := &decodeState{opts: &test.opts}
+			st.prescan(input)
+			capacity, prepaid := st.listCapacity(input)
+			if capacity != test.capacity || prepaid != capacity || st.allocated != int64(capacity)*listElementCost {
+				t.Errorf("%+v: Got capacity %d prepaid %d allocated %d Wanted capacity %d", test.opts, capacity, prepaid, st.allocated, test.capacity)
+			}
+
+			_, err := ParseWithOptions(input, test.opts)
+			if !errors.Is(err, test.err) {
+				t.Errorf("%+v: Got %v Wanted %v", test.opts, err, test.err)
+			}
+		}
+
+		// Limits still fail at the same element as without PreScan
+		_, want := ParseWithOptions(input, Options{MaxListLength: 10})
+		_, got := ParseWithOptions(input, Options{PreScan: true, MaxListLength: 10})
+		if got == nil || got.Error() != want.Error() {
+			t.Errorf("Got %v Wanted %v", got, want)
+		}
+	})
+
+	t.Run("Testing errors are unchanged", func(t *testing.T) {
+		for _, input := range []string{"li1ei2e", "d1:ai1e1:bi01ee", "l5:abce"} {
+			_, want := Parse(input)
+			_, got := ParseWithOptions(input, Options{PreScan: true})
+			if got == nil || got.Error() != want.Error() {
+				t.Errorf("%q: Got %v Wanted %v", input, got, want)
+			}
+		}
+	})
+}
+
+// benchmarkTorrent builds a multi-file torrent whose pieces are random
+// binary data, the case EstimateCapacity handles worst
+func benchmarkTorrent(files int) string {
+	r := rand.New(rand.NewSource(1))
+	pieces := make([]byte, 20*files)
+	r.Read(pieces)
+
+	var builder strings.Builder
+	builder.WriteString("d8:announce8:test.com4:infod5:filesl")
+	for i := 0; i < files; i++ {
+		name := fmt.Sprintf("file%d.bin", i)
+		fmt.Fprintf(&builder, "d6:lengthi%de4:pathl3:dir%d:%see", r.Intn(1<<30), len(name), name)
+	}
+	fmt.Fprintf(&builder, "e4:name4:test12:piece lengthi262144e6:pieces%d:%see", len(pieces), pieces)
+	return builder.String()
+}
+
+// BenchmarkCapacityStrategies compares the default capacities, the
+// EstimateCapacity heuristic and the exact pre-scan
+func BenchmarkCapacityStrategies(b *testing.B) {
+	for _, files := range []int{10, 1000, 100000} {
+		input := benchmarkTorrent(files)
+		listCap, dictCap := EstimateCapacity(input)
+
+		strategies := []struct {
+			name string
+			opts Options
+		}{
+			{"Default", Options{}},
+			{"EstimateCapacity", Options{ListCapacity: listCap, DictCapacity: dictCap}},
+			{"PreScan", Options{PreScan: true}},
+		}
+
+		for _, strategy := range strategies {
+			b.Run(fmt.Sprintf("%s/%dFiles", strategy.name, files), func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(input)))
+				for i := 0; i < b.N; i++ {
+					if _, err := ParseWithOptions(input, strategy.opts); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
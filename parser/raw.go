@@ -0,0 +1,20 @@
+package parser
+
+// RawValue is a complete bencoded value kept as its original bytes.
+//
+// Struct fields of type RawValue capture the exact input that Unmarshal saw
+// for them, and Options.RawKeys makes the parser return selected dictionary
+// values this way. Hashing a RawValue gives the same result as hashing the
+// corresponding span of the input, which is what a torrent's info-hash needs.
+type RawValue string
+
+// Parse decodes the raw value into the representation Parse returns
+func (r RawValue) Parse() (any, error) {
+	return Parse(string(r))
+}
+
+// MarshalBencode returns the raw bytes unchanged, so that encoding a
+// RawValue reproduces the original input
+func (r RawValue) MarshalBencode() ([]byte, error) {
+	return []byte(r), nil
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"crypto/sha1"
+	"reflect"
+	"testing"
+)
+
+const rawTorrent = "d8:announce8:test.com4:infod6:lengthi5e4:name4:test12:piece lengthi16e6:pieces20:xxxxxxxxxxxxxxxxxxxxee"
+
+const rawInfo = "d6:lengthi5e4:name4:test12:piece lengthi16e6:pieces20:xxxxxxxxxxxxxxxxxxxxe"
+
+func TestRawKeys(t *testing.T) {
+	t.Run("Testing info dictionary is returned raw", func(t *testing.T) {
+		got, err := ParseWithOptions(rawTorrent, Options{RawKeys: []string{"info"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		info, ok := got.(map[string]any)["info"].(RawValue)
+		if !ok {
+			t.Fatalf("Expected info to be a RawValue, got %T", got.(map[string]any)["info"])
+		}
+		if string(info) != rawInfo {
+			t.Errorf("Got %q Wanted %q", info, rawInfo)
+		}
+		if sha1.Sum([]byte(info)) != sha1.Sum([]byte(rawInfo)) {
+			t.Error("Hash of raw info does not match hash of the input span")
+		}
+
+		decoded, err := info.Parse()
+		if err != nil {
+			t.Fatalf("Unexpected error parsing raw value: %v", err)
+		}
+		if decoded.(map[string]any)["name"] != "test" {
+			t.Errorf("Got %v Wanted name \"test\"", decoded)
+		}
+	})
+
+	t.Run("Testing raw keys at any depth", func(t *testing.T) {
+		got, err := ParseWithOptions("ld1:xli1eeed1:xi2eee", Options{RawKeys: []string{"x"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := []any{
+			map[string]any{"x": RawValue("li1ee")},
+			map[string]any{"x": RawValue("i2e")},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Got %v Wanted %v", got, want)
+		}
+	})
+
+	t.Run("Testing invalid raw value is still rejected", func(t *testing.T) {
+		if _, err := ParseWithOptions("d4:infod1:ai01eee", Options{RawKeys: []string{"info"}}); err == nil {
+			t.Error("Expected error for malformed raw value, got nil")
+		}
+	})
+}
+
+func TestUnmarshalRawValue(t *testing.T) {
+	var got struct {
+		Announce string   `bencode:"announce"`
+		Info     RawValue `bencode:"info"`
+	}
+	if err := Unmarshal([]byte(rawTorrent), &got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.Announce != "test.com" || string(got.Info) != rawInfo {
+		t.Errorf("Got %+v Wanted info %q", got, rawInfo)
+	}
+}
@@ -0,0 +1,256 @@
+package parser
+
+import (
+	"io"
+	"strconv"
+)
+
+// TokenKind identifies the kind of a Token
+type TokenKind int
+
+const (
+	DictStart TokenKind = iota + 1 // 'd' opening a dictionary
+	ListStart                      // 'l' opening a list
+	End                            // 'e' closing the innermost list or dictionary
+	Int                            // an integer
+	String                         // a string, either a dictionary key or a value
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case DictStart:
+		return "DictStart"
+	case ListStart:
+		return "ListStart"
+	case End:
+		return "End"
+	case Int:
+		return "Int"
+	case String:
+		return "String"
+	}
+	return "TokenKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Token is a single syntactic element of bencoded input
+type Token struct {
+	Kind   TokenKind
+	Offset int64  // byte offset of the token's first byte in the input
+	End    int64  // byte offset just past the token
+	Int    int64  // value of an Int token, zero when it only fits a *big.Int under NumberBigInt
+	Value  string // data of a String token, or the digits of an Int token
+}
+
+// Tokenizer walks bencoded input one token at a time without building
+// values. Reading from an io.Reader, it buffers only the token at hand, so
+// arbitrarily large documents can be scanned, filtered or indexed using
+// memory proportional to their nesting depth and largest string. It accepts
+// exactly the input ParseWithOptions accepts with the same options and
+// reports the same *SyntaxError offsets and paths.
+type Tokenizer struct {
+	input string // the input, or the buffered part of it when reading from in
+	pos   int
+	base  int64 // offset of input within the whole input
+
+	in   *readBuffer // the stream being read, nil for a string
+	leaf int         // bytes of an unfinished integer already searched, see scanLeaf
+
+	stack []tokenFrame // open lists and dictionaries, innermost last
+	st    decodeState
+	done  bool // a complete top-level value has been read
+	err   error
+}
+
+// tokenFrame tracks an open list or dictionary
+type tokenFrame struct {
+	dict   bool
+	n      int    // elements or entries completed so far
+	key    string // key of the entry being read, or of the last one read
+	hasKey bool   // key has been read and its value has not yet completed
+}
+
+// NewTokenizer returns a tokenizer over a single bencoded value in str
+func NewTokenizer(str string) *Tokenizer {
+	return NewTokenizerWithOptions(str, Options{})
+}
+
+// NewTokenizerBytes returns a tokenizer over data without copying it. String
+// tokens share data's memory and are only valid while it is unmodified.
+func NewTokenizerBytes(data []byte) *Tokenizer {
+	return NewTokenizer(bytesView(data))
+}
+
+// NewTokenizerReader returns a tokenizer over a single bencoded value read
+// from r. It reads r in chunks and may read past the end of the value.
+func NewTokenizerReader(r io.Reader) *Tokenizer {
+	return NewTokenizerReaderWithOptions(r, Options{})
+}
+
+// Next returns the next token. It returns io.EOF once the value and the
+// input are complete. Errors are sticky.
+func (t *Tokenizer) Next() (Token, error) {
+	if t.err != nil {
+		return Token{}, t.err
+	}
+	if t.in != nil {
+		if err := t.fill(); err != nil {
+			t.err = err
+			return Token{}, err
+		}
+	}
+	tok, err := t.next()
+	if err != nil {
+		t.err = err
+	}
+	return tok, err
+}
+
+// fill reads until the buffer holds the next token or the input is
+// exhausted. Malformed input is handed to next as-is so that it reports the
+// same errors as Parse.
+func (t *Tokenizer) fill() error {
+	t.in.pos = t.pos // the tokens returned so far are consumed
+	scan := func(buf []byte) int { return scanToken(buf, t.st.opts, &t.leaf) }
+	_, err := t.in.fill(scan, t.st.opts.MaxTotalAllocation, "token")
+	if err == io.EOF {
+		err = nil
+	}
+	t.input, t.pos, t.base = bytesView(t.in.buf), t.in.pos, t.in.base
+	return err
+}
+
+// Skip consumes the rest of the innermost open list or dictionary, up to and
+// including its End token. Call it after a DictStart or ListStart to skip
+// that value entirely.
+func (t *Tokenizer) Skip() error {
+	depth := len(t.stack)
+	for len(t.stack) >= depth && depth > 0 {
+		if _, err := t.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Depth returns the number of lists and dictionaries currently open
+func (t *Tokenizer) Depth() int {
+	return len(t.stack)
+}
+
+func (t *Tokenizer) next() (Token, error) {
+	s := t.input[t.pos:]
+	if t.done {
+		if len(s) > 0 {
+			return Token{}, t.fail(syntaxError(s, 0, "end of input", "extra data after value"), false)
+		}
+		return Token{}, io.EOF
+	}
+
+	if len(t.stack) > 0 {
+		top := &t.stack[len(t.stack)-1]
+		switch {
+		case len(s) == 0 && top.hasKey:
+			return Token{}, t.fail(syntaxError(s, 0, "value", "dictionary missing value for key %s", quote(top.key)), false)
+		case len(s) == 0:
+			return Token{}, t.fail(missingEnd(s, top.dict), false)
+
+		case s[0] == 'e' && !top.hasKey:
+			t.stack = t.stack[:len(t.stack)-1]
+			t.st.leave()
+			return t.emit(Token{Kind: End}, 1, true), nil
+
+		case !top.hasKey:
+			// s starts the next element of a list or entry of a dictionary
+			if err := t.st.addElement(s, top.dict, top.n+1, 0); err != nil {
+				return Token{}, t.fail(err, false)
+			}
+			if top.dict {
+				key, remaining, err := scanEntryKey(s, &t.st, top.n, top.key)
+				if err != nil {
+					return Token{}, t.fail(err, false)
+				}
+				top.key = t.st.clone(key)
+				top.hasKey = true
+				return t.emit(Token{Kind: String, Value: top.key}, len(s)-len(remaining), false), nil
+			}
+		}
+	} else if len(s) == 0 {
+		return Token{}, t.fail(syntaxError(s, 0, "value", "empty string for parsing Bencode value"), true)
+	}
+
+	switch s[0] {
+	case 'i':
+		eIndex, err := scanInteger(s, &t.st)
+		if err != nil {
+			return Token{}, t.fail(err, true)
+		}
+		tok := Token{Kind: Int, Value: t.st.clone(s[1:eIndex])}
+		if t.st.opts.Numbers == NumberBigInt {
+			var n any
+			n, err = parseBigIntegerDigits(s, eIndex)
+			tok.Int, _ = n.(int64)
+		} else {
+			tok.Int, err = parseIntegerDigits(s, eIndex)
+		}
+		if err != nil {
+			return Token{}, t.fail(err, true)
+		}
+		return t.emit(tok, eIndex+1, true), nil
+
+	case 'l', 'd':
+		if err := t.st.enter(s); err != nil {
+			return Token{}, t.fail(err, true)
+		}
+		kind := ListStart
+		if s[0] == 'd' {
+			kind = DictStart
+		}
+		tok := t.emit(Token{Kind: kind}, 1, false)
+		t.stack = append(t.stack, tokenFrame{dict: s[0] == 'd'})
+		return tok, nil
+
+	default:
+		val, remaining, err := scanString(s, &t.st)
+		if err != nil {
+			return Token{}, t.fail(err, true)
+		}
+		return t.emit(Token{Kind: String, Value: t.st.clone(val)}, len(s)-len(remaining), true), nil
+	}
+}
+
+// emit fills in the position of tok, which spans the next n bytes, and
+// advances past it. completes reports whether tok ends a value.
+func (t *Tokenizer) emit(tok Token, n int, completes bool) Token {
+	tok.Offset = t.base + int64(t.pos)
+	tok.End = tok.Offset + int64(n)
+	t.pos += n
+
+	if completes {
+		if len(t.stack) == 0 {
+			t.done = true
+		} else {
+			top := &t.stack[len(t.stack)-1]
+			top.n++
+			top.hasKey = false
+		}
+	}
+	return tok
+}
+
+// fail locates err and gives it the key path of the current position.
+// inValue reports whether err concerns a value inside the innermost
+// container, rather than the container itself.
+func (t *Tokenizer) fail(err error, inValue bool) error {
+	for i := len(t.stack) - 1; i >= 0; i-- {
+		f := t.stack[i]
+		if i == len(t.stack)-1 && !inValue {
+			continue
+		}
+		if f.dict {
+			err = prefixPath(err, f.key)
+		} else {
+			err = prefixPath(err, "["+strconv.Itoa(f.n)+"]")
+		}
+	}
+	return locateError(err, t.input, t.base)
+}
@@ -0,0 +1,240 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// tokenize returns every token of input, or the first error
+func tokenize(input string) ([]Token, error) {
+	return tokenizeAll(NewTokenizer(input))
+}
+
+// tokenizeReader is tokenize reading input one byte at a time
+func tokenizeReader(input string) ([]Token, error) {
+	return tokenizeAll(NewTokenizerReader(iotest.OneByteReader(strings.NewReader(input))))
+}
+
+func tokenizeAll(tok *Tokenizer) ([]Token, error) {
+	var tokens []Token
+	for {
+		token, err := tok.Next()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestTokenizer(t *testing.T) {
+	t.Run("Testing token stream", func(t *testing.T) {
+		tokens, err := tokenize("d3:bari-42e3:fool4:spamdeee")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		readerTokens, err := tokenizeReader("d3:bari-42e3:fool4:spamdeee")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []Token{
+			{Kind: DictStart, Offset: 0, End: 1},
+			{Kind: String, Offset: 1, End: 6, Value: "bar"},
+			{Kind: Int, Offset: 6, End: 11, Int: -42, Value: "-42"},
+			{Kind: String, Offset: 11, End: 16, Value: "foo"},
+			{Kind: ListStart, Offset: 16, End: 17},
+			{Kind: String, Offset: 17, End: 23, Value: "spam"},
+			{Kind: DictStart, Offset: 23, End: 24},
+			{Kind: End, Offset: 24, End: 25},
+			{Kind: End, Offset: 25, End: 26},
+			{Kind: End, Offset: 26, End: 27},
+		}
+		if len(tokens) != len(expected) {
+			t.Fatalf("Got %v Wanted %v", tokens, expected)
+		}
+		for i := range expected {
+			if tokens[i] != expected[i] {
+				t.Errorf("Token %d: Got %+v Wanted %+v", i, tokens[i], expected[i])
+			}
+			if i >= len(readerTokens) || readerTokens[i] != expected[i] {
+				t.Errorf("Reader token %d: Got %+v Wanted %+v", i, readerTokens, expected[i])
+			}
+		}
+	})
+
+	t.Run("Testing Skip", func(t *testing.T) {
+		tok := NewTokenizer("d5:filesld6:lengthi1eee4:name4:teste")
+		var keys []string
+		for {
+			token, err := tok.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if token.Kind == String && tok.Depth() == 1 {
+				keys = append(keys, token.Value)
+			}
+			if token.Kind == ListStart {
+				if err := tok.Skip(); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+		}
+
+		if len(keys) != 3 || keys[0] != "files" || keys[1] != "name" || keys[2] != "test" {
+			t.Errorf("Got %v Wanted %v", keys, []string{"files", "name", "test"})
+		}
+	})
+
+	t.Run("Testing errors match Parse", func(t *testing.T) {
+		inputs := []string{
+			"",
+			"iabce",
+			"li1e3abc",
+			"d4:infod5:filesld6:lengthi1eed6:lengthi01eeeee",
+			"d6:pieces100:abc",
+			"d3:foo3:bar",
+			"d3:foo",
+			"li1e",
+			"i1ei2e",
+			"di42e3:bare",
+			"e",
+		}
+
+		for _, input := range inputs {
+			_, parseErr := Parse(input)
+			for _, tokenizer := range []func(string) ([]Token, error){tokenize, tokenizeReader} {
+				_, tokErr := tokenizer(input)
+
+				var want, got *SyntaxError
+				if !errors.As(parseErr, &want) || !errors.As(tokErr, &got) {
+					t.Errorf("%q: Got %v Wanted %v", input, tokErr, parseErr)
+					continue
+				}
+				if got.Offset != want.Offset || got.Path != want.Path || got.Expected != want.Expected {
+					t.Errorf("%q: Got %d %q %q Wanted %d %q %q", input, got.Offset, got.Path, got.Expected, want.Offset, want.Path, want.Expected)
+				}
+			}
+		}
+	})
+
+	t.Run("Testing options match Parse", func(t *testing.T) {
+		tests := []struct {
+			input string
+			opts  Options
+		}{
+			{"d1:bi1e1:ai2ee", Options{Strict: true}},
+			{"d1:ai1e1:ai2ee", Options{Strict: true}},
+			{"ld1:ai1eei-0ee", Options{Strict: true}},
+			{"d1:ali1ei2ei3eee", Options{MaxListLength: 2}},
+			{"ld1:ai1e1:bi2eee", Options{MaxDictEntries: 1}},
+			{"l4:spam4:spam4:spame", Options{MaxTotalAllocation: 50}},
+			{"li1ei99999999999999999999ee", Options{}},
+		}
+
+		for _, test := range tests {
+			_, parseErr := ParseWithOptions(test.input, test.opts)
+			tokenizers := []*Tokenizer{
+				NewTokenizerWithOptions(test.input, test.opts),
+				NewTokenizerReaderWithOptions(iotest.OneByteReader(strings.NewReader(test.input)), test.opts),
+			}
+			for _, tok := range tokenizers {
+				_, tokErr := tokenizeAll(tok)
+
+				var want, got *SyntaxError
+				if !errors.As(parseErr, &want) || !errors.As(tokErr, &got) {
+					t.Errorf("%q: Got %v Wanted %v", test.input, tokErr, parseErr)
+					continue
+				}
+				if got.Error() != want.Error() || got.Err != want.Err {
+					t.Errorf("%q: Got %v Wanted %v", test.input, got, want)
+				}
+			}
+		}
+	})
+
+	t.Run("Testing big integers", func(t *testing.T) {
+		tokens, err := tokenizeAll(NewTokenizerWithOptions("li99999999999999999999ei7ee", Options{Numbers: NumberBigInt}))
+		if err != nil || len(tokens) != 4 {
+			t.Fatalf("Got %v, %v Wanted 4 tokens", tokens, err)
+		}
+		if tokens[1].Value != "99999999999999999999" || tokens[1].Int != 0 || tokens[2].Int != 7 {
+			t.Errorf("Got %+v %+v Wanted the digits of both integers", tokens[1], tokens[2])
+		}
+	})
+
+	t.Run("Testing depth limit", func(t *testing.T) {
+		_, err := tokenize(strings.Repeat("l", DefaultMaxDepth+1))
+		if !errors.Is(err, ErrMaxDepth) {
+			t.Errorf("Got %v Wanted %v", err, ErrMaxDepth)
+		}
+	})
+
+	t.Run("Testing reader in constant memory", func(t *testing.T) {
+		// 8 MB of list elements, generated as they are read
+		const elements = 1 << 20
+		input := io.MultiReader(
+			strings.NewReader("l"),
+			io.LimitReader(repeatReader("4:spam"), 6*elements),
+			strings.NewReader("e"),
+		)
+		tok := NewTokenizerReader(input)
+		tokens, err := tokenizeAll(tok)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(tokens) != elements+2 || tokens[elements+1].End != 6*elements+2 {
+			t.Errorf("Got %d tokens ending at %d", len(tokens), tokens[len(tokens)-1].End)
+		}
+		if cap(tok.in.buf) > 4096 {
+			t.Errorf("Got buffer of %d bytes", cap(tok.in.buf))
+		}
+	})
+
+	t.Run("Testing long runs of digits", func(t *testing.T) {
+		// Both must fail promptly: the integer once its 'e' arrives, the
+		// length prefix once it outgrows any int
+		digits := strings.Repeat("1", 1<<20)
+		tests := []struct {
+			input string
+			want  error
+		}{
+			{"i" + digits + "e", strconv.ErrRange},
+			{digits + ":", nil},
+		}
+		for _, test := range tests {
+			tok := NewTokenizerReader(iotest.OneByteReader(strings.NewReader(test.input)))
+			_, err := tokenizeAll(tok)
+			if err == nil || (test.want != nil && !errors.Is(err, test.want)) {
+				t.Errorf("Got %v Wanted %v", err, test.want)
+			}
+		}
+	})
+
+	t.Run("Testing reader errors", func(t *testing.T) {
+		readErr := errors.New("read failed")
+		tok := NewTokenizerReader(io.MultiReader(strings.NewReader("li1e"), iotest.ErrReader(readErr)))
+		if _, err := tokenizeAll(tok); !errors.Is(err, readErr) {
+			t.Errorf("Got %v Wanted %v", err, readErr)
+		}
+	})
+}
+
+// repeatReader endlessly repeats a string
+type repeatReader string
+
+func (r repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r[i%len(r)]
+	}
+	return len(p) - len(p)%len(r), nil
+}
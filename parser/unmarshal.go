@@ -0,0 +1,366 @@
+package parser
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// UnmarshalTypeError describes a bencoded value that could not be stored in
+// a Go value of the target type
+type UnmarshalTypeError struct {
+	Value string       // bencode kind: "integer", "string", "list" or "dictionary"
+	Type  reflect.Type // Go type it could not be assigned to
+	Path  string       // key path of the value, e.g. "info.files[3].length"
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("unmarshal error: cannot decode %s into Go value of type %s", e.Value, e.Type)
+	}
+	return fmt.Sprintf("unmarshal error: cannot decode %s into Go value of type %s at %q", e.Value, e.Type, e.Path)
+}
+
+// InvalidUnmarshalError describes an invalid argument passed to Unmarshal
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "unmarshal error: target is nil"
+	}
+	if e.Type.Kind() != reflect.Pointer {
+		return fmt.Sprintf("unmarshal error: target must be a pointer, got %s", e.Type)
+	}
+	return fmt.Sprintf("unmarshal error: target is a nil %s", e.Type)
+}
+
+// Unmarshaler is implemented by types that decode their own bencoding, the
+// counterpart of encoder.Marshaler. UnmarshalBencode receives a copy of a
+// single valid value that it may keep.
+type Unmarshaler interface {
+	UnmarshalBencode([]byte) error
+}
+
+// ValueUnmarshaler is implemented by types that decode themselves from the
+// value Parse returns rather than from its bytes. The value is parsed in the
+// caller's pass over the input, under its options and limits, so that
+// recursive types don't parse their input again at every level. Dictionaries
+// are always map[string]any and strings always string, whatever OrderedDicts
+// and RawKeys say.
+type ValueUnmarshaler interface {
+	UnmarshalBencodeValue(any) error
+}
+
+// Unmarshal parses the bencoded data and stores the result in the value
+// pointed to by v.
+//
+// Dictionaries decode into structs, matching keys against the field's
+// `bencode:"name"` tag or, without a tag, the field name. Fields tagged "-"
+// and keys without a matching field are skipped. Dictionaries also decode
+// into maps with string keys, lists into slices and arrays, integers into any
+// integer kind (and 0 or 1 into bool) and strings into strings, byte slices
+// and byte arrays of the same length. Pointers are allocated as needed, an
+// empty interface receives the same values Parse returns and a RawValue
+// receives the undecoded bytes of its value. Integers of any size decode
+// into big.Int, and a value whose pointer implements ValueUnmarshaler or
+// Unmarshaler decodes itself.
+func Unmarshal(data []byte, v any) error {
+	return unmarshal(data, v, &decodeState{opts: &defaultOptions, cloneStrings: true})
+}
+
+func unmarshal(data []byte, v any, st *decodeState) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	input := bytesView(data)
+	remaining, err := unmarshalValue(input, rv.Elem(), st)
+	if err != nil {
+		return locateError(err, input, 0)
+	}
+
+	if len(remaining) > 0 {
+		return locateError(syntaxError(remaining, 0, "end of input", "extra data after value"), input, 0)
+	}
+
+	return nil
+}
+
+// unmarshalValue decodes the value at the start of s into v and returns the
+// input that follows it
+func unmarshalValue(s string, v reflect.Value, st *decodeState) (string, error) {
+	if len(s) == 0 {
+		return "", syntaxError(s, 0, "value", "empty string for parsing Bencode value")
+	}
+
+	v = indirect(v)
+	if v.Type() == rawValueType {
+		// Validate without decoding and keep the bytes it consumed
+		remaining, err := skipValue(s, st)
+		if err != nil {
+			return "", err
+		}
+		v.SetString(st.clone(s[:len(s)-len(remaining)]))
+		return remaining, nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(valueUnmarshalerType) {
+		return unmarshalWithValue(s, v.Addr().Interface().(ValueUnmarshaler), st)
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		remaining, err := skipValue(s, st)
+		if err != nil {
+			return "", err
+		}
+		return remaining, v.Addr().Interface().(Unmarshaler).UnmarshalBencode([]byte(s[:len(s)-len(remaining)]))
+	}
+	if v.Type() == bigIntType {
+		return unmarshalBigInt(s, v, st)
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		val, remaining, err := parseValue(s, st)
+		if err != nil {
+			return "", err
+		}
+		v.Set(reflect.ValueOf(val))
+		return remaining, nil
+	}
+
+	switch s[0] {
+	case 'l':
+		return unmarshalList(s, v, st)
+	case 'd':
+		return unmarshalDictionary(s, v, st)
+	}
+
+	// Integers and strings are parsed by the core parser and then assigned
+	val, remaining, err := parseValue(s, st)
+	if err != nil {
+		return "", err
+	}
+
+	switch val := val.(type) {
+	case int64:
+		err = setInteger(v, val)
+	case *big.Int:
+		err = &UnmarshalTypeError{Value: "integer " + val.String(), Type: v.Type()}
+	case string:
+		err = setString(v, val)
+	}
+	return remaining, err
+}
+
+// unmarshalBigInt decodes an integer of any size into a big.Int, whatever
+// the configured NumberMode
+func unmarshalBigInt(s string, v reflect.Value, st *decodeState) (string, error) {
+	if s[0] != 'i' {
+		return "", &UnmarshalTypeError{Value: kindOf(s), Type: v.Type()}
+	}
+
+	// An integer token never nests or allocates, so a fresh state will do
+	bigOpts := *st.opts
+	bigOpts.Numbers = NumberBigInt
+	val, remaining, err := parseValue(s, &decodeState{opts: &bigOpts})
+	if err != nil {
+		return "", err
+	}
+
+	z := v.Addr().Interface().(*big.Int)
+	switch n := val.(type) {
+	case int64:
+		z.SetInt64(n)
+	case *big.Int:
+		z.Set(n)
+	}
+	return remaining, nil
+}
+
+// unmarshalWithValue parses the value at the start of s in the plain
+// representation ValueUnmarshaler promises and hands it to u
+func unmarshalWithValue(s string, u ValueUnmarshaler, st *decodeState) (string, error) {
+	opts := *st.opts
+	opts.OrderedDicts, opts.RawKeys = false, nil
+	callerOpts := st.opts
+	st.opts = &opts
+	val, remaining, err := parseValue(s, st)
+	st.opts = callerOpts
+	if err != nil {
+		return "", err
+	}
+	return remaining, u.UnmarshalBencodeValue(val)
+}
+
+// kindOf names the kind of the bencoded value at the start of s
+func kindOf(s string) string {
+	switch s[0] {
+	case 'i':
+		return "integer"
+	case 'l':
+		return "list"
+	case 'd':
+		return "dictionary"
+	}
+	return "string"
+}
+
+// indirect follows pointers, allocating them when nil
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func setInteger(v reflect.Value, n int64) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(n) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("integer %d", n), Type: v.Type()}
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("integer %d", n), Type: v.Type()}
+		}
+		v.SetUint(uint64(n))
+	case reflect.Bool: // Matches the encoder, which writes booleans as i1e and i0e
+		if n != 0 && n != 1 {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("integer %d", n), Type: v.Type()}
+		}
+		v.SetBool(n == 1)
+	default:
+		return &UnmarshalTypeError{Value: "integer", Type: v.Type()}
+	}
+	return nil
+}
+
+func setString(v reflect.Value, s string) error {
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes([]byte(s))
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		if len(s) != v.Len() {
+			return &UnmarshalTypeError{Value: fmt.Sprintf("string of length %d", len(s)), Type: v.Type()}
+		}
+		reflect.Copy(v, reflect.ValueOf([]byte(s)))
+	default:
+		return &UnmarshalTypeError{Value: "string", Type: v.Type()}
+	}
+	return nil
+}
+
+func unmarshalList(s string, v reflect.Value, st *decodeState) (string, error) {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", &UnmarshalTypeError{Value: "list", Type: v.Type()}
+	}
+
+	i := 0
+	remaining, err := walkContainer(s, st, 0, func(current, _ string, _ int) (string, error) {
+		var remaining string
+		var err error
+		switch {
+		case v.Kind() == reflect.Slice:
+			if i >= v.Cap() {
+				v.Set(reflect.Append(v.Slice(0, i), reflect.Zero(v.Type().Elem())))
+			}
+			v.SetLen(i + 1)
+			remaining, err = unmarshalValue(current, v.Index(i), st)
+		case i < v.Len():
+			remaining, err = unmarshalValue(current, v.Index(i), st)
+		default: // Elements beyond the end of an array are discarded
+			remaining, err = skipValue(current, st)
+		}
+		i++
+		return remaining, err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case v.Kind() == reflect.Slice && v.IsNil():
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	case v.Kind() == reflect.Slice:
+		v.SetLen(i)
+	default:
+		for ; i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+	}
+
+	return remaining, nil
+}
+
+func unmarshalDictionary(s string, v reflect.Value, st *decodeState) (string, error) {
+	var fields map[string]int
+	switch {
+	case v.Kind() == reflect.Struct:
+		fields = cachedFields(v.Type())
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	default:
+		return "", &UnmarshalTypeError{Value: "dictionary", Type: v.Type()}
+	}
+
+	return walkContainer(s, st, 0, func(current, key string, _ int) (string, error) {
+		if v.Kind() == reflect.Struct {
+			if index, ok := fields[key]; ok {
+				return unmarshalValue(current, v.Field(index), st)
+			}
+			return skipValue(current, st)
+		}
+
+		elem := reflect.New(v.Type().Elem()).Elem()
+		remaining, err := unmarshalValue(current, elem, st)
+		if err == nil {
+			v.SetMapIndex(reflect.ValueOf(st.clone(key)).Convert(v.Type().Key()), elem)
+		}
+		return remaining, err
+	})
+}
+
+var (
+	rawValueType         = reflect.TypeFor[RawValue]()
+	bigIntType           = reflect.TypeFor[big.Int]()
+	unmarshalerType      = reflect.TypeFor[Unmarshaler]()
+	valueUnmarshalerType = reflect.TypeFor[ValueUnmarshaler]()
+)
+
+var fieldCache sync.Map // map[reflect.Type]map[string]int
+
+// cachedFields maps the dictionary keys of struct type t to field indexes
+func cachedFields(t reflect.Type) map[string]int {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(map[string]int)
+	}
+
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("bencode"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields[name] = i
+	}
+
+	f, _ := fieldCache.LoadOrStore(t, fields)
+	return f.(map[string]int)
+}
@@ -0,0 +1,250 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testFile struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+}
+
+type testInfo struct {
+	Name        string     `bencode:"name"`
+	PieceLength int        `bencode:"piece length"`
+	Pieces      []byte     `bencode:"pieces"`
+	Files       []testFile `bencode:"files,omitempty"`
+	Private     *int       `bencode:"private,omitempty"`
+}
+
+// upperString decodes a bencoded string in upper case
+type upperString string
+
+func (u *upperString) UnmarshalBencode(data []byte) error {
+	var s string
+	if err := Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*u = upperString(strings.ToUpper(s))
+	return nil
+}
+
+// parsedValue keeps the value it is decoded from
+type parsedValue struct {
+	value any
+}
+
+func (p *parsedValue) UnmarshalBencodeValue(v any) error {
+	p.value = v
+	return nil
+}
+
+type testTorrent struct {
+	Announce string         `bencode:"announce"`
+	Info     testInfo       `bencode:"info"`
+	Comment  string         `bencode:"-"`
+	Extra    map[string]any `bencode:"extra"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("Testing torrent-like struct", func(t *testing.T) {
+		data := "d8:announce8:test.com7:comment4:skip5:extrad1:ai1ee" +
+			"4:infod5:filesld6:lengthi10e4:pathl1:a1:beee" +
+			"4:name4:test12:piece lengthi262144e6:pieces4:abcd7:privatei1eee"
+
+		var got testTorrent
+		if err := Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		private := 1
+		want := testTorrent{
+			Announce: "test.com",
+			Info: testInfo{
+				Name:        "test",
+				PieceLength: 262144,
+				Pieces:      []byte("abcd"),
+				Files:       []testFile{{Length: 10, Path: []string{"a", "b"}}},
+				Private:     &private,
+			},
+			Extra: map[string]any{"a": int64(1)},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Got %+v Wanted %+v", got, want)
+		}
+	})
+
+	t.Run("Testing slices, arrays and maps", func(t *testing.T) {
+		var list []uint16
+		if err := Unmarshal([]byte("li1ei2ei3ee"), &list); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(list, []uint16{1, 2, 3}) {
+			t.Errorf("Got %v Wanted %v", list, []uint16{1, 2, 3})
+		}
+
+		var array [2]string
+		if err := Unmarshal([]byte("l1:a1:b1:ce"), &array); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if array != [2]string{"a", "b"} {
+			t.Errorf("Got %v Wanted %v", array, [2]string{"a", "b"})
+		}
+
+		var hash [4]byte
+		if err := Unmarshal([]byte("4:abcd"), &hash); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(hash[:]) != "abcd" {
+			t.Errorf("Got %q Wanted %q", hash, "abcd")
+		}
+
+		var flags []bool
+		if err := Unmarshal([]byte("li1ei0ee"), &flags); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(flags, []bool{true, false}) {
+			t.Errorf("Got %v Wanted %v", flags, []bool{true, false})
+		}
+
+		var dict map[string][]int
+		if err := Unmarshal([]byte("d1:ali1ee1:blee"), &dict); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := map[string][]int{"a": {1}, "b": {}}
+		if !reflect.DeepEqual(dict, want) {
+			t.Errorf("Got %v Wanted %v", dict, want)
+		}
+	})
+
+	t.Run("Testing unknown keys and extra elements are skipped", func(t *testing.T) {
+		// Values with nowhere to go are validated but never built
+		data := []byte("d1:ai1e1:bl" + strings.Repeat("4:spam", 100) + "e1:cd1:xi1e1:yi2eee")
+		var v struct {
+			A int `bencode:"a"`
+		}
+		list := []byte("li1eld1:xi1eeee")
+		var array [1]int
+		allocs := testing.AllocsPerRun(10, func() {
+			if err := Unmarshal(data, &v); err != nil || v.A != 1 {
+				t.Fatalf("Got %v, %v Wanted 1", v.A, err)
+			}
+			if err := Unmarshal(list, &array); err != nil || array[0] != 1 {
+				t.Fatalf("Got %v, %v Wanted [1]", array, err)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("Got %v allocations Wanted 0", allocs)
+		}
+
+		if err := Unmarshal([]byte("d1:ai1e1:bli1xee"), &v); err == nil {
+			t.Error("Expected error for malformed unknown value, got nil")
+		}
+	})
+
+	t.Run("Testing empty interface", func(t *testing.T) {
+		var got any
+		if err := Unmarshal([]byte("d3:fooli1eee"), &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := map[string]any{"foo": []any{int64(1)}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Got %v Wanted %v", got, want)
+		}
+	})
+
+	t.Run("Testing type mismatch reports key path", func(t *testing.T) {
+		data := "d4:infod5:filesld6:lengthi1eed6:length3:badeeee"
+
+		var got testTorrent
+		err := Unmarshal([]byte(data), &got)
+
+		var typeErr *UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("Expected *UnmarshalTypeError, got %v", err)
+		}
+		if typeErr.Path != "info.files[1].length" {
+			t.Errorf("Got path %q Wanted %q", typeErr.Path, "info.files[1].length")
+		}
+		if typeErr.Value != "string" || typeErr.Type.Kind() != reflect.Int64 {
+			t.Errorf("Unexpected error details: %v", typeErr)
+		}
+	})
+
+	t.Run("Testing integer overflow", func(t *testing.T) {
+		var got struct {
+			Small int8
+			Count uint
+		}
+		if err := Unmarshal([]byte("d5:Smalli300ee"), &got); err == nil {
+			t.Error("Expected overflow error, got nil")
+		}
+		if err := Unmarshal([]byte("d5:Counti-1ee"), &got); err == nil {
+			t.Error("Expected error for negative unsigned value, got nil")
+		}
+	})
+
+	t.Run("Testing invalid targets", func(t *testing.T) {
+		var invalid *InvalidUnmarshalError
+		var notPointer testTorrent
+		if err := Unmarshal([]byte("de"), notPointer); !errors.As(err, &invalid) {
+			t.Errorf("Expected *InvalidUnmarshalError for non-pointer, got %v", err)
+		}
+		if err := Unmarshal([]byte("de"), nil); !errors.As(err, &invalid) {
+			t.Errorf("Expected *InvalidUnmarshalError for nil, got %v", err)
+		}
+	})
+
+	t.Run("Testing malformed input and extra data", func(t *testing.T) {
+		var got testTorrent
+		if err := Unmarshal([]byte("d8:announce"), &got); err == nil {
+			t.Error("Expected error for truncated dictionary, got nil")
+		}
+		if err := Unmarshal([]byte("dei1e"), &got); err == nil {
+			t.Error("Expected error for extra data, got nil")
+		}
+	})
+
+	t.Run("Testing Unmarshaler", func(t *testing.T) {
+		var got struct {
+			Name  upperString            `bencode:"name"`
+			Alias *upperString           `bencode:"alias"`
+			Tags  map[string]upperString `bencode:"tags"`
+		}
+		data := "d5:alias1:b4:name3:abc4:tagsd1:x2:yzee"
+		if err := Unmarshal([]byte(data), &got); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got.Name != "ABC" || got.Alias == nil || *got.Alias != "B" || got.Tags["x"] != "YZ" {
+			t.Errorf("Got %+v", got)
+		}
+
+		var typeErr *UnmarshalTypeError
+		if err := Unmarshal([]byte("d4:tagsd1:xi1eee"), &got); !errors.As(err, &typeErr) || typeErr.Path != "tags.x" {
+			t.Errorf("Got %v Wanted *UnmarshalTypeError at \"tags.x\"", err)
+		}
+	})
+	t.Run("Testing ValueUnmarshaler", func(t *testing.T) {
+		var got struct {
+			Value parsedValue `bencode:"v"`
+		}
+		opts := Options{OrderedDicts: true, RawKeys: []string{"a"}}
+		if err := UnmarshalWithOptions([]byte("d1:vd1:ai1e1:bl1:xeee"), &got, opts); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected := map[string]any{"a": int64(1), "b": []any{"x"}}
+		if !reflect.DeepEqual(got.Value.value, expected) {
+			t.Errorf("Got %#v Wanted %#v", got.Value.value, expected)
+		}
+
+		// The value counts towards the caller's depth
+		err := UnmarshalWithOptions([]byte("d1:vllleee"), &got, Options{MaxDepth: 3})
+		var syntaxErr *SyntaxError
+		if !errors.Is(err, ErrMaxDepth) || !errors.As(err, &syntaxErr) || syntaxErr.Path != "v[0][0]" {
+			t.Errorf("Got %v Wanted %v at \"v[0][0]\"", err, ErrMaxDepth)
+		}
+	})
+}